@@ -0,0 +1,152 @@
+package ambisonics
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+)
+
+// Speaker is a speaker of a Layout at the direction given by Azimuth and Elevation in radians. The
+// LFE speaker, the subwoofer, has no direction and stays silent, the bass management is up to the
+// playback system.
+type Speaker struct {
+	Azimuth, Elevation float64
+	LFE                bool
+}
+
+// Layout is the arrangement of speakers to decode to. The speakers are in the order of the
+// channels.
+type Layout []Speaker
+
+var (
+	// StereoLayout decodes to two virtual microphones pointing to the left and the right, for a
+	// pair of stereo speakers or headphones. Use beep.Stereo points.
+	StereoLayout = Layout{{Azimuth: math.Pi / 2}, {Azimuth: -math.Pi / 2}}
+	// QuadLayout is a square of four speakers in the order front left, front right, back left and
+	// back right. Use Quad points.
+	QuadLayout = Layout{{Azimuth: deg(45)}, {Azimuth: deg(-45)}, {Azimuth: deg(135)}, {Azimuth: deg(-135)}}
+	// Surround51Layout is the 5.1 layout of ITU-R BS.775 in the order front left, front right,
+	// center, LFE, surround left and surround right. Use Surround51 points.
+	Surround51Layout = Layout{
+		{Azimuth: deg(30)}, {Azimuth: deg(-30)}, {}, {LFE: true}, {Azimuth: deg(110)}, {Azimuth: deg(-110)},
+	}
+)
+
+func deg(x float64) float64 {
+	return x * math.Pi / 180
+}
+
+// DecoderType selects how the sound field is decoded.
+type DecoderType int
+
+const (
+	// BasicDecoder reconstructs the sound field exactly in the center of the speakers, which
+	// suits a single listener, but sounds thin and out of phase for everyone else.
+	BasicDecoder DecoderType = iota
+	// MaxREDecoder concentrates the energy of each source in its direction, which localizes it
+	// better at higher frequencies and in a larger area. It's the usual choice.
+	MaxREDecoder
+)
+
+// String returns the name of the decoder type.
+func (t DecoderType) String() string {
+	switch t {
+	case BasicDecoder:
+		return "BasicDecoder"
+	case MaxREDecoder:
+		return "MaxREDecoder"
+	}
+	return fmt.Sprintf("DecoderType(%d)", int(t))
+}
+
+// Decode decodes the sound field of s for the speakers of the layout, one channel of P per
+// speaker. Any further channels of P are silent. If P has fewer channels than the layout has
+// speakers, Decode panics.
+//
+// The decoder projects the sound field onto the directions of the speakers. If all the speakers
+// are in the horizontal plane, it's decoded in 2D and the height is ignored. The decoding is best
+// with speakers spread evenly around the listener, for irregular layouts like 5.1 it's only an
+// approximation.
+//
+// The returned Streamer propagates s's errors through Err.
+func Decode[S beep.Size, P beep.Point[S]](s beep.Streamer[S, BFormat[S]], layout Layout, typ DecoderType) beep.Streamer[S, P] {
+	var p P
+	if p.Count() < len(layout) {
+		panic(fmt.Errorf("ambisonics: decode: %d speakers don't fit in %d channels", len(layout), p.Count()))
+	}
+	return &decoder[S, P]{s: s, m: decodeMatrix(layout, typ)}
+}
+
+// decodeMatrix returns the gains of the W, Y, Z and X channels for each speaker.
+func decodeMatrix(layout Layout, typ DecoderType) [][4]float64 {
+	speakers, flat := 0, true
+	for _, sp := range layout {
+		if !sp.LFE {
+			speakers++
+			flat = flat && sp.Elevation == 0
+		}
+	}
+
+	// the weight of the first order, which sets how directional the decoded speakers are
+	order := 3.0
+	weight := 1.0
+	if flat {
+		order = 2
+		if typ == MaxREDecoder {
+			weight = math.Cos(math.Pi / 4)
+		}
+	} else if typ == MaxREDecoder {
+		weight = math.Cos(deg(137.9) / 2.51)
+	}
+
+	m := make([][4]float64, len(layout))
+	for i, sp := range layout {
+		if sp.LFE {
+			continue
+		}
+		g := encodeGains(sp.Azimuth, sp.Elevation)
+		if flat {
+			g[2] = 0
+		}
+		m[i][0] = 1 / float64(speakers)
+		for c := 1; c < 4; c++ {
+			m[i][c] = order * weight * g[c] / float64(speakers)
+		}
+	}
+	return m
+}
+
+type decoder[S beep.Size, P beep.Point[S]] struct {
+	s   beep.Streamer[S, BFormat[S]]
+	m   [][4]float64
+	buf []BFormat[S]
+}
+
+func (d *decoder[S, P]) Stream(samples []P) (n int, ok bool) {
+	if len(d.buf) < len(samples) {
+		d.buf = make([]BFormat[S], len(samples))
+	}
+	n, ok = d.s.Stream(d.buf[:len(samples)])
+	i := 0
+	points.Each(samples[:n], func(ch []S) {
+		b := d.buf[i]
+		i++
+		for c := range ch {
+			ch[c] = 0
+			if c < len(d.m) {
+				var y float64
+				for k, g := range d.m[c] {
+					y += g * float64(b[k])
+				}
+				ch[c] = S(y)
+			}
+		}
+	})
+	return n, ok
+}
+
+func (d *decoder[S, P]) Err() error {
+	return d.s.Err()
+}
@@ -0,0 +1,79 @@
+package ambisonics_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/ambisonics"
+)
+
+func encoded(azimuth float64) beep.Streamer[float64, ambisonics.BFormat[float64]] {
+	return &ambisonics.Encoder[float64, beep.Mono[float64]]{Streamer: ones(), Azimuth: azimuth}
+}
+
+func TestDecodeStereo(t *testing.T) {
+	for _, typ := range []ambisonics.DecoderType{ambisonics.BasicDecoder, ambisonics.MaxREDecoder} {
+		samples := make([]beep.Stereo[float64], 4)
+
+		ambisonics.Decode[float64, beep.Stereo[float64]](encoded(0), ambisonics.StereoLayout, typ).Stream(samples)
+		if math.Abs(samples[3][0]-0.5) > 1e-9 || math.Abs(samples[3][1]-0.5) > 1e-9 {
+			t.Errorf("%v: front: got %v, want [0.5 0.5]", typ, samples[3])
+		}
+
+		ambisonics.Decode[float64, beep.Stereo[float64]](encoded(math.Pi/2), ambisonics.StereoLayout, typ).Stream(samples)
+		if samples[3][0] <= 1 || samples[3][1] >= 0 {
+			t.Errorf("%v: left: got %v", typ, samples[3])
+		}
+	}
+}
+
+func TestDecodeQuad(t *testing.T) {
+	samples := make([]ambisonics.Quad[float64], 4)
+	ambisonics.Decode[float64, ambisonics.Quad[float64]](encoded(math.Pi/4), ambisonics.QuadLayout, ambisonics.MaxREDecoder).Stream(samples)
+
+	// the front left speaker is the loudest and the gains sum to one
+	p, sum := samples[3], 0.0
+	for c := range p {
+		sum += p[c]
+		if c > 0 && p[c] >= p[0] {
+			t.Errorf("speaker %d is as loud as the front left: %v", c, p)
+		}
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Errorf("got the sum %v, want 1", sum)
+	}
+	if math.Abs(p[1]-p[2]) > 1e-9 {
+		t.Errorf("front right and back left differ: %v", p)
+	}
+}
+
+func TestDecodeSurround51(t *testing.T) {
+	samples := make([]ambisonics.Surround51[float64], 4)
+	ambisonics.Decode[float64, ambisonics.Surround51[float64]](encoded(0), ambisonics.Surround51Layout, ambisonics.MaxREDecoder).Stream(samples)
+	p := samples[3]
+	if p[3] != 0 {
+		t.Errorf("LFE: got %v, want 0", p[3])
+	}
+	if p[2] <= p[0] || p[0] <= p[4] || math.Abs(p[0]-p[1]) > 1e-9 {
+		t.Errorf("front: got %v, want center loudest and the sides symmetric", p)
+	}
+}
+
+func TestDecodePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	ambisonics.Decode[float64, beep.Stereo[float64]](encoded(0), ambisonics.QuadLayout, ambisonics.BasicDecoder)
+}
+
+func TestDecoderTypeString(t *testing.T) {
+	if s := ambisonics.MaxREDecoder.String(); s != "MaxREDecoder" {
+		t.Errorf("got %q", s)
+	}
+	if s := ambisonics.DecoderType(7).String(); s != "DecoderType(7)" {
+		t.Errorf("got %q", s)
+	}
+}
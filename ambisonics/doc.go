@@ -0,0 +1,11 @@
+// Package ambisonics implements first-order Ambisonics, which records or synthesizes a whole
+// sound field around a listener instead of the signals of particular speakers.
+//
+// The sound field travels through streamers as BFormat points with the four channels W, Y, Z and X
+// in the ACN order and with the SN3D normalization, the AmbiX convention used by 360° video. An
+// Encoder places mono sources in the sound field, a Rotator turns the sound field, for example to
+// follow the head or the camera, and Decode renders it for a speaker Layout.
+//
+// Directions are given by the azimuth, counterclockwise from the front, so positive to the left,
+// and the elevation, positive upwards, both in radians, as usual in Ambisonics.
+package ambisonics
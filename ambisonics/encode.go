@@ -0,0 +1,70 @@
+package ambisonics
+
+import (
+	"math"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+)
+
+// Encoder places the wrapped Streamer, mixed down to mono, in the sound field at the direction
+// given by Azimuth and Elevation in radians.
+//
+// Changes of the direction are ramped over the next call to Stream, so moving sources don't click.
+// If you're playing the Encoder through the speaker, lock the speaker when modifying its fields.
+type Encoder[S beep.Size, P beep.Point[S]] struct {
+	Streamer           beep.Streamer[S, P]
+	Azimuth, Elevation float64
+
+	buf     []P
+	gains   [4]float64 // gains of the channels at the end of the last Stream
+	started bool
+}
+
+// Stream streams the wrapped Streamer encoded to B-format.
+func (e *Encoder[S, P]) Stream(samples []BFormat[S]) (n int, ok bool) {
+	if len(e.buf) < len(samples) {
+		e.buf = make([]P, len(samples))
+	}
+	n, ok = e.Streamer.Stream(e.buf[:len(samples)])
+
+	target := encodeGains(e.Azimuth, e.Elevation)
+	if !e.started {
+		e.gains, e.started = target, true
+	}
+
+	i := 0
+	points.Each(e.buf[:n], func(ch []S) {
+		var mono float64
+		for _, x := range ch {
+			mono += float64(x)
+		}
+		mono /= float64(len(ch))
+
+		// ramp the gains linearly over the streamed samples
+		f := float64(i+1) / float64(n)
+		for c := range samples[i] {
+			samples[i][c] = S(mono * (e.gains[c] + f*(target[c]-e.gains[c])))
+		}
+		i++
+	})
+	if n > 0 {
+		e.gains = target
+	}
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (e *Encoder[S, P]) Err() error {
+	return e.Streamer.Err()
+}
+
+// encodeGains returns the gains of the B-format channels of a plane wave from the direction.
+func encodeGains(azimuth, elevation float64) [4]float64 {
+	return [4]float64{
+		1,
+		math.Sin(azimuth) * math.Cos(elevation),
+		math.Sin(elevation),
+		math.Cos(azimuth) * math.Cos(elevation),
+	}
+}
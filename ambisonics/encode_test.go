@@ -0,0 +1,72 @@
+package ambisonics_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/ambisonics"
+)
+
+// ones streams a constant of 1 forever.
+func ones() beep.Streamer[float64, beep.Mono[float64]] {
+	return beep.StreamerFunc[float64, beep.Mono[float64]](func(samples []beep.Mono[float64]) (int, bool) {
+		for i := range samples {
+			samples[i] = beep.Mono[float64]{1}
+		}
+		return len(samples), true
+	})
+}
+
+func near(a, b ambisonics.BFormat[float64]) bool {
+	for c := range a {
+		if math.Abs(a[c]-b[c]) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestEncoder(t *testing.T) {
+	tests := []struct {
+		azimuth, elevation float64
+		want               ambisonics.BFormat[float64]
+	}{
+		{0, 0, ambisonics.BFormat[float64]{1, 0, 0, 1}},
+		{math.Pi / 2, 0, ambisonics.BFormat[float64]{1, 1, 0, 0}},
+		{-math.Pi / 2, 0, ambisonics.BFormat[float64]{1, -1, 0, 0}},
+		{math.Pi, 0, ambisonics.BFormat[float64]{1, 0, 0, -1}},
+		{0, math.Pi / 2, ambisonics.BFormat[float64]{1, 0, 1, 0}},
+		{math.Pi / 4, 0, ambisonics.BFormat[float64]{1, math.Sqrt2 / 2, 0, math.Sqrt2 / 2}},
+	}
+	for _, test := range tests {
+		e := &ambisonics.Encoder[float64, beep.Mono[float64]]{Streamer: ones(), Azimuth: test.azimuth, Elevation: test.elevation}
+		samples := make([]ambisonics.BFormat[float64], 16)
+		n, ok := e.Stream(samples)
+		if n != 16 || !ok {
+			t.Fatalf("got %d %v", n, ok)
+		}
+		for _, p := range samples {
+			if !near(p, test.want) {
+				t.Errorf("azimuth %v, elevation %v: got %v, want %v", test.azimuth, test.elevation, p, test.want)
+				break
+			}
+		}
+	}
+}
+
+func TestEncoderRamp(t *testing.T) {
+	e := &ambisonics.Encoder[float64, beep.Mono[float64]]{Streamer: ones()}
+	samples := make([]ambisonics.BFormat[float64], 10)
+	e.Stream(samples)
+
+	e.Azimuth = math.Pi / 2
+	e.Stream(samples)
+	// the gains move linearly from the front to the left over the samples
+	if !near(samples[4], ambisonics.BFormat[float64]{1, 0.5, 0, 0.5}) {
+		t.Errorf("got %v in the middle", samples[4])
+	}
+	if !near(samples[9], ambisonics.BFormat[float64]{1, 1, 0, 0}) {
+		t.Errorf("got %v at the end", samples[9])
+	}
+}
@@ -0,0 +1,80 @@
+package ambisonics
+
+import "github.com/faiface/beep"
+
+// BFormat is a point of a first-order Ambisonics sound field with the channels W, Y, Z and X in
+// the ACN order and with the SN3D normalization. W is the omnidirectional pressure, X points to the
+// front, Y to the left and Z up.
+type BFormat[S beep.Size] [4]S
+
+func (p BFormat[S]) Count() int {
+	return 4
+}
+func (p BFormat[S]) Set(index int, v S) beep.Point[S] {
+	p[index] = v
+	return p
+}
+func (p BFormat[S]) Add(index int, v S) beep.Point[S] {
+	p[index] += v
+	return p
+}
+func (p BFormat[S]) Get(index int) S {
+	return p[index]
+}
+func (p BFormat[S]) Slice() []S {
+	return p[:]
+}
+
+// W returns the omnidirectional channel.
+func (p BFormat[S]) W() S { return p[0] }
+
+// Y returns the left-right channel.
+func (p BFormat[S]) Y() S { return p[1] }
+
+// Z returns the up-down channel.
+func (p BFormat[S]) Z() S { return p[2] }
+
+// X returns the front-back channel.
+func (p BFormat[S]) X() S { return p[3] }
+
+// Quad is a point of four speaker channels, as decoded for the QuadLayout.
+type Quad[S beep.Size] [4]S
+
+func (p Quad[S]) Count() int {
+	return 4
+}
+func (p Quad[S]) Set(index int, v S) beep.Point[S] {
+	p[index] = v
+	return p
+}
+func (p Quad[S]) Add(index int, v S) beep.Point[S] {
+	p[index] += v
+	return p
+}
+func (p Quad[S]) Get(index int) S {
+	return p[index]
+}
+func (p Quad[S]) Slice() []S {
+	return p[:]
+}
+
+// Surround51 is a point of six speaker channels, as decoded for the Surround51Layout.
+type Surround51[S beep.Size] [6]S
+
+func (p Surround51[S]) Count() int {
+	return 6
+}
+func (p Surround51[S]) Set(index int, v S) beep.Point[S] {
+	p[index] = v
+	return p
+}
+func (p Surround51[S]) Add(index int, v S) beep.Point[S] {
+	p[index] += v
+	return p
+}
+func (p Surround51[S]) Get(index int) S {
+	return p[index]
+}
+func (p Surround51[S]) Slice() []S {
+	return p[:]
+}
@@ -0,0 +1,80 @@
+package ambisonics
+
+import (
+	"math"
+
+	"github.com/faiface/beep"
+)
+
+// Rotator rotates the sound field of the wrapped Streamer. The sound field is first rolled by Roll
+// around the front axis, raising the left side, then pitched by Pitch around the left axis, raising
+// the front, and then turned by Yaw around the vertical axis, moving the front to the left. The
+// angles are in radians.
+//
+// To keep the sound field in place while the head or the camera turns, rotate it by the opposite
+// angles.
+//
+// Changes of the angles are ramped over the next call to Stream, so the sound field doesn't jump.
+// If you're playing the Rotator through the speaker, lock the speaker when modifying its fields.
+type Rotator[S beep.Size] struct {
+	Streamer         beep.Streamer[S, BFormat[S]]
+	Yaw, Pitch, Roll float64
+
+	m       [3][3]float64 // rotation of the last Stream
+	started bool
+}
+
+// Stream streams the wrapped Streamer rotated.
+func (r *Rotator[S]) Stream(samples []BFormat[S]) (n int, ok bool) {
+	n, ok = r.Streamer.Stream(samples)
+
+	target := rotation(r.Yaw, r.Pitch, r.Roll)
+	if !r.started {
+		r.m, r.started = target, true
+	}
+
+	for i := range samples[:n] {
+		// ramp the rotation linearly over the streamed samples
+		f := float64(i+1) / float64(n)
+		v := [3]float64{float64(samples[i][3]), float64(samples[i][1]), float64(samples[i][2])}
+		var out [3]float64
+		for row := range out {
+			for col := range v {
+				out[row] += (r.m[row][col] + f*(target[row][col]-r.m[row][col])) * v[col]
+			}
+		}
+		samples[i][3], samples[i][1], samples[i][2] = S(out[0]), S(out[1]), S(out[2])
+	}
+	if n > 0 {
+		r.m = target
+	}
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (r *Rotator[S]) Err() error {
+	return r.Streamer.Err()
+}
+
+// rotation returns the matrix which rotates the vector (X, Y, Z) as described by Rotator.
+func rotation(yaw, pitch, roll float64) [3][3]float64 {
+	cy, sy := math.Cos(yaw), math.Sin(yaw)
+	cp, sp := math.Cos(pitch), math.Sin(pitch)
+	cr, sr := math.Cos(roll), math.Sin(roll)
+	rz := [3][3]float64{{cy, -sy, 0}, {sy, cy, 0}, {0, 0, 1}}
+	ry := [3][3]float64{{cp, 0, -sp}, {0, 1, 0}, {sp, 0, cp}}
+	rx := [3][3]float64{{1, 0, 0}, {0, cr, -sr}, {0, sr, cr}}
+	return mul(rz, mul(ry, rx))
+}
+
+func mul(a, b [3][3]float64) [3][3]float64 {
+	var m [3][3]float64
+	for i := range m {
+		for j := range m[i] {
+			for k := range b {
+				m[i][j] += a[i][k] * b[k][j]
+			}
+		}
+	}
+	return m
+}
@@ -0,0 +1,37 @@
+package ambisonics_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/ambisonics"
+)
+
+func TestRotator(t *testing.T) {
+	tests := []struct {
+		name               string
+		yaw, pitch, roll   float64
+		azimuth, elevation float64
+		want               ambisonics.BFormat[float64]
+	}{
+		{"yaw moves the front to the left", math.Pi / 2, 0, 0, 0, 0, ambisonics.BFormat[float64]{1, 1, 0, 0}},
+		{"pitch raises the front", 0, math.Pi / 2, 0, 0, 0, ambisonics.BFormat[float64]{1, 0, 1, 0}},
+		{"roll raises the left", 0, 0, math.Pi / 2, math.Pi / 2, 0, ambisonics.BFormat[float64]{1, 0, 1, 0}},
+		{"roll keeps the front", 0, 0, 1, 0, 0, ambisonics.BFormat[float64]{1, 0, 0, 1}},
+		{"roll moves up to the right, yaw the right to the front", math.Pi / 2, 0, math.Pi / 2, 0, math.Pi / 2, ambisonics.BFormat[float64]{1, 0, 0, 1}},
+	}
+	for _, test := range tests {
+		r := &ambisonics.Rotator[float64]{
+			Streamer: &ambisonics.Encoder[float64, beep.Mono[float64]]{Streamer: ones(), Azimuth: test.azimuth, Elevation: test.elevation},
+			Yaw:      test.yaw,
+			Pitch:    test.pitch,
+			Roll:     test.roll,
+		}
+		samples := make([]ambisonics.BFormat[float64], 8)
+		r.Stream(samples)
+		if !near(samples[7], test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, samples[7], test.want)
+		}
+	}
+}
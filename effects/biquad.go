@@ -0,0 +1,301 @@
+package effects
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"sync"
+	"sync/atomic"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+)
+
+// FilterType selects the response of a Biquad.
+type FilterType int
+
+const (
+	// LowPass passes frequencies below Freq and attenuates those above it by 12 dB per octave.
+	LowPass FilterType = iota
+	// HighPass passes frequencies above Freq and attenuates those below it by 12 dB per octave.
+	HighPass
+	// BandPass passes frequencies around Freq with a peak gain of 0 dB.
+	BandPass
+	// Notch removes frequencies around Freq.
+	Notch
+	// AllPass passes all frequencies unchanged, but shifts their phase around Freq.
+	AllPass
+	// LowShelf boosts or cuts frequencies below Freq by Gain.
+	LowShelf
+	// HighShelf boosts or cuts frequencies above Freq by Gain.
+	HighShelf
+	// Peaking boosts or cuts frequencies around Freq by Gain.
+	Peaking
+)
+
+// String returns the name of the filter type.
+func (t FilterType) String() string {
+	switch t {
+	case LowPass:
+		return "LowPass"
+	case HighPass:
+		return "HighPass"
+	case BandPass:
+		return "BandPass"
+	case Notch:
+		return "Notch"
+	case AllPass:
+		return "AllPass"
+	case LowShelf:
+		return "LowShelf"
+	case HighShelf:
+		return "HighShelf"
+	case Peaking:
+		return "Peaking"
+	}
+	return fmt.Sprintf("FilterType(%d)", int(t))
+}
+
+// Biquad describes a second order filter section. The coefficients are calculated using the
+// formulas from Robert Bristow-Johnson's Audio EQ Cookbook:
+// https://www.w3.org/TR/audio-eq-cookbook/
+type Biquad struct {
+	// Type selects the shape of the response.
+	Type FilterType
+
+	// Freq is the cutoff, center or corner frequency in Hertz [Hz], depending on Type. It must be
+	// between 0 and half the sample rate.
+	Freq float64
+
+	// Q controls the width of the response around Freq. Higher values mean narrower band pass,
+	// notch and peaking filters, and a resonant peak for low and high pass filters. Zero means
+	// 1/√2, which is a Butterworth response for low and high pass filters and a shelf without
+	// overshoot. Use BandwidthToQ to specify the width in octaves instead.
+	Q float64
+
+	// Gain is the boost (positive) or cut (negative) in decibels [dB]. It's only used by
+	// LowShelf, HighShelf and Peaking.
+	Gain float64
+}
+
+// BandwidthToQ returns the Q of a filter which is bw octaves wide.
+func BandwidthToQ(bw float64) float64 {
+	p := math.Pow(2, bw)
+	return math.Sqrt(p) / (p - 1)
+}
+
+// Response returns the magnitude response (linear gain) of the Biquad at the frequency freq, given
+// the sample rate sr.
+func (b Biquad) Response(sr beep.SampleRate, freq float64) float64 {
+	return b.coefs(sr).response(2 * math.Pi * freq / float64(sr))
+}
+
+// Butterworth returns the sections of a Butterworth filter of the given order, which has the
+// flattest possible pass band and rolls off by 6 dB per octave for each order. The typ must be
+// LowPass or HighPass and order must be a positive even number, otherwise Butterworth panics.
+func Butterworth(typ FilterType, freq float64, order int) []Biquad {
+	if typ != LowPass && typ != HighPass {
+		panic(fmt.Errorf("effects: butterworth: invalid filter type: %v", typ))
+	}
+	if order <= 0 || order%2 != 0 {
+		panic(fmt.Errorf("effects: butterworth: invalid order: %d", order))
+	}
+	stages := make([]Biquad, order/2)
+	for k := range stages {
+		theta := math.Pi * float64(2*k+1) / float64(2*order)
+		stages[k] = Biquad{Type: typ, Freq: freq, Q: 1 / (2 * math.Cos(theta))}
+	}
+	return stages
+}
+
+// LinkwitzRiley returns the sections of a Linkwitz-Riley filter of the given order, which is -6 dB
+// at freq. The low pass and the high pass filters of the same frequency and order sum to a flat
+// response, which makes them the usual choice for crossovers. For orders 2, 6, 10 and so on, the
+// two are out of phase and one of them must be inverted for the sum to be flat. The typ must be
+// LowPass or HighPass and order must be a positive even number, otherwise LinkwitzRiley panics.
+func LinkwitzRiley(typ FilterType, freq float64, order int) []Biquad {
+	if typ != LowPass && typ != HighPass {
+		panic(fmt.Errorf("effects: linkwitz-riley: invalid filter type: %v", typ))
+	}
+	if order <= 0 || order%2 != 0 {
+		panic(fmt.Errorf("effects: linkwitz-riley: invalid order: %d", order))
+	}
+
+	// a Linkwitz-Riley filter is a Butterworth filter of half the order applied twice
+	half := order / 2
+	var stages []Biquad
+	if half%2 != 0 {
+		// two first order Butterworth sections make a second order section with Q of 1/2
+		stages = append(stages, Biquad{Type: typ, Freq: freq, Q: 0.5})
+	}
+	for k := 0; k < half/2; k++ {
+		theta := math.Pi * float64(2*k+1) / float64(2*half)
+		b := Biquad{Type: typ, Freq: freq, Q: 1 / (2 * math.Sin(theta))}
+		stages = append(stages, b, b)
+	}
+	return stages
+}
+
+// Filter is a Streamer which runs the wrapped Streamer through a cascade of biquad sections. Each
+// channel is filtered separately.
+//
+// The sections may be changed with SetStages at any time, even from another goroutine while the
+// Filter is being streamed. The change takes effect at the beginning of the next call to Stream.
+type Filter[S beep.Size, P beep.Point[S]] struct {
+	s      beep.Streamer[S, P]
+	sr     beep.SampleRate
+	stages []Biquad
+	mu     sync.Mutex // guards stages
+	coefs  atomic.Pointer[[]biquadCoefs]
+	state  [][]biquadState // per section, per channel
+}
+
+// NewFilter returns a Filter of s with the given sections. The SampleRate (sr) must match that of
+// the Streamer.
+//
+//	// remove rumble below 30 Hz with a steep slope
+//	f := effects.NewFilter(s, sr, effects.Butterworth(effects.HighPass, 30, 4)...)
+func NewFilter[S beep.Size, P beep.Point[S]](s beep.Streamer[S, P], sr beep.SampleRate, stages ...Biquad) *Filter[S, P] {
+	f := &Filter[S, P]{s: s, sr: sr}
+	f.SetStages(stages...)
+	return f
+}
+
+// SetStages replaces the sections of the Filter. If the number of sections stays the same, the
+// state of the sections is kept, which avoids clicks when sweeping parameters.
+func (f *Filter[S, P]) SetStages(stages ...Biquad) {
+	coefs := make([]biquadCoefs, len(stages))
+	for i, b := range stages {
+		coefs[i] = b.coefs(f.sr)
+	}
+	f.mu.Lock()
+	f.stages = append([]Biquad(nil), stages...)
+	f.coefs.Store(&coefs)
+	f.mu.Unlock()
+}
+
+// Stages returns a copy of the current sections of the Filter.
+func (f *Filter[S, P]) Stages() []Biquad {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Biquad(nil), f.stages...)
+}
+
+// Stream streams the wrapped Streamer filtered by the sections.
+func (f *Filter[S, P]) Stream(samples []P) (n int, ok bool) {
+	n, ok = f.s.Stream(samples)
+	coefs := *f.coefs.Load()
+	if len(f.state) != len(coefs) {
+		var p P
+		f.state = make([][]biquadState, len(coefs))
+		for i := range f.state {
+			f.state[i] = make([]biquadState, p.Count())
+		}
+	}
+	points.Each(samples[:n], func(ch []S) {
+		for j, co := range coefs {
+			st := f.state[j]
+			for c := range ch {
+				ch[c] = S(st[c].process(co, float64(ch[c])))
+			}
+		}
+	})
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (f *Filter[S, P]) Err() error {
+	return f.s.Err()
+}
+
+// biquadCoefs are the coefficients of a biquad section, normalized so that a0 is 1.
+type biquadCoefs struct {
+	b0, b1, b2, a1, a2 float64
+}
+
+func (b Biquad) coefs(sr beep.SampleRate) biquadCoefs {
+	q := b.Q
+	if q <= 0 {
+		q = math.Sqrt2 / 2
+	}
+	w0 := 2 * math.Pi * b.Freq / float64(sr)
+	cos, sin := math.Cos(w0), math.Sin(w0)
+	alpha := sin / (2 * q)
+	A := math.Pow(10, b.Gain/40)
+
+	var b0, b1, b2, a0, a1, a2 float64
+	switch b.Type {
+	case LowPass:
+		b0, b1, b2 = (1-cos)/2, 1-cos, (1-cos)/2
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	case HighPass:
+		b0, b1, b2 = (1+cos)/2, -(1 + cos), (1+cos)/2
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	case BandPass:
+		b0, b1, b2 = alpha, 0, -alpha
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	case Notch:
+		b0, b1, b2 = 1, -2*cos, 1
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	case AllPass:
+		b0, b1, b2 = 1-alpha, -2*cos, 1+alpha
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	case LowShelf:
+		sq := 2 * math.Sqrt(A) * alpha
+		b0 = A * ((A + 1) - (A-1)*cos + sq)
+		b1 = 2 * A * ((A - 1) - (A+1)*cos)
+		b2 = A * ((A + 1) - (A-1)*cos - sq)
+		a0 = (A + 1) + (A-1)*cos + sq
+		a1 = -2 * ((A - 1) + (A+1)*cos)
+		a2 = (A + 1) + (A-1)*cos - sq
+	case HighShelf:
+		sq := 2 * math.Sqrt(A) * alpha
+		b0 = A * ((A + 1) + (A-1)*cos + sq)
+		b1 = -2 * A * ((A - 1) + (A+1)*cos)
+		b2 = A * ((A + 1) + (A-1)*cos - sq)
+		a0 = (A + 1) - (A-1)*cos + sq
+		a1 = 2 * ((A - 1) - (A+1)*cos)
+		a2 = (A + 1) - (A-1)*cos - sq
+	case Peaking:
+		b0, b1, b2 = 1+alpha*A, -2*cos, 1-alpha*A
+		a0, a1, a2 = 1+alpha/A, -2*cos, 1-alpha/A
+	default:
+		panic(fmt.Errorf("effects: biquad: invalid filter type: %v", b.Type))
+	}
+
+	return biquadCoefs{b0 / a0, b1 / a0, b2 / a0, a1 / a0, a2 / a0}
+}
+
+// response returns the magnitude response at the angular frequency w (radians per sample).
+func (c biquadCoefs) response(w float64) float64 {
+	z1 := complex(math.Cos(w), -math.Sin(w))
+	z2 := z1 * z1
+	num := complex(c.b0, 0) + complex(c.b1, 0)*z1 + complex(c.b2, 0)*z2
+	den := 1 + complex(c.a1, 0)*z1 + complex(c.a2, 0)*z2
+	return cmplx.Abs(num / den)
+}
+
+// biquadState is the history of one channel of a biquad section in direct form I. Direct form I
+// keeps the input and output history, so it stays well behaved when the coefficients change.
+type biquadState struct {
+	x1, x2, y1, y2 float64
+}
+
+func (s *biquadState) process(c biquadCoefs, x float64) float64 {
+	y := c.b0*x + c.b1*s.x1 + c.b2*s.x2 - c.a1*s.y1 - c.a2*s.y2
+	s.x2, s.x1 = s.x1, x
+	s.y2, s.y1 = s.y1, y
+	return y
+}
+
+// lerp interpolates linearly between the coefficients c and d. The result is stable if both c and
+// d are, because the region of stable second order sections is convex.
+func (c biquadCoefs) lerp(d biquadCoefs, t float64) biquadCoefs {
+	return biquadCoefs{
+		b0: c.b0 + (d.b0-c.b0)*t,
+		b1: c.b1 + (d.b1-c.b1)*t,
+		b2: c.b2 + (d.b2-c.b2)*t,
+		a1: c.a1 + (d.a1-c.a1)*t,
+		a2: c.a2 + (d.a2-c.a2)*t,
+	}
+}
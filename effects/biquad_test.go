@@ -0,0 +1,91 @@
+package effects_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+)
+
+func dB(x float64) float64 {
+	return 20 * math.Log10(x)
+}
+
+func cascadeResponse(stages []effects.Biquad, sr beep.SampleRate, freq float64) float64 {
+	g := 1.0
+	for _, b := range stages {
+		g *= b.Response(sr, freq)
+	}
+	return g
+}
+
+func TestBiquadResponse(t *testing.T) {
+	const sr = beep.SampleRate(48000)
+	for _, tc := range []struct {
+		name   string
+		stages []effects.Biquad
+		freq   float64
+		want   float64 // dB
+	}{
+		{"lowpass-cutoff", []effects.Biquad{{Type: effects.LowPass, Freq: 1000}}, 1000, -3.01},
+		{"lowpass-pass", []effects.Biquad{{Type: effects.LowPass, Freq: 1000}}, 20, 0},
+		{"highpass-cutoff", []effects.Biquad{{Type: effects.HighPass, Freq: 1000}}, 1000, -3.01},
+		{"notch-center", []effects.Biquad{{Type: effects.Notch, Freq: 1000, Q: 2}}, 1000, math.Inf(-1)},
+		{"bandpass-center", []effects.Biquad{{Type: effects.BandPass, Freq: 1000, Q: 2}}, 1000, 0},
+		{"allpass", []effects.Biquad{{Type: effects.AllPass, Freq: 1000}}, 3000, 0},
+		{"peaking-center", []effects.Biquad{{Type: effects.Peaking, Freq: 1000, Q: 1, Gain: 6}}, 1000, 6},
+		{"lowshelf-low", []effects.Biquad{{Type: effects.LowShelf, Freq: 1000, Gain: -9}}, 10, -9},
+		{"lowshelf-high", []effects.Biquad{{Type: effects.LowShelf, Freq: 1000, Gain: -9}}, 20000, 0},
+		{"highshelf-high", []effects.Biquad{{Type: effects.HighShelf, Freq: 1000, Gain: 4}}, 23000, 4},
+		{"butterworth4-cutoff", effects.Butterworth(effects.LowPass, 1000, 4), 1000, -3.01},
+		{"butterworth4-octave", effects.Butterworth(effects.HighPass, 1000, 4), 250, -48.2},
+		{"linkwitzriley2-cutoff", effects.LinkwitzRiley(effects.LowPass, 1000, 2), 1000, -6.02},
+		{"linkwitzriley4-cutoff", effects.LinkwitzRiley(effects.HighPass, 1000, 4), 1000, -6.02},
+		{"linkwitzriley6-cutoff", effects.LinkwitzRiley(effects.HighPass, 1000, 6), 1000, -6.02},
+		{"linkwitzriley8-cutoff", effects.LinkwitzRiley(effects.LowPass, 1000, 8), 1000, -6.02},
+		{"linkwitzriley10-cutoff", effects.LinkwitzRiley(effects.LowPass, 1000, 10), 1000, -6.02},
+	} {
+		got := dB(cascadeResponse(tc.stages, sr, tc.freq))
+		if math.IsInf(tc.want, -1) {
+			if got > -100 {
+				t.Errorf("%s: got %.2f dB, want -Inf", tc.name, got)
+			}
+			continue
+		}
+		if math.Abs(got-tc.want) > 0.1 {
+			t.Errorf("%s: got %.2f dB, want %.2f dB", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestFilterStream(t *testing.T) {
+	const (
+		sr   = beep.SampleRate(44100)
+		freq = 5000.0
+	)
+	var phase float64
+	sine := beep.StreamerFunc[float64, beep.Stereo[float64]](func(samples []beep.Stereo[float64]) (n int, ok bool) {
+		for i := range samples {
+			v := math.Sin(phase)
+			samples[i] = beep.Stereo[float64]{v, v}
+			phase += 2 * math.Pi * freq / float64(sr)
+		}
+		return len(samples), true
+	})
+
+	stages := effects.Butterworth(effects.LowPass, 500, 2)
+	f := effects.NewFilter[float64, beep.Stereo[float64]](sine, sr, stages...)
+	buf := make([]beep.Stereo[float64], 4410)
+	f.Stream(buf) // let the filter settle
+	f.Stream(buf)
+
+	var peak float64
+	for _, p := range buf {
+		peak = math.Max(peak, math.Abs(p[0]))
+	}
+	want := cascadeResponse(stages, sr, freq)
+	if math.Abs(peak-want) > 0.01 {
+		t.Errorf("got peak %f, want %f", peak, want)
+	}
+}
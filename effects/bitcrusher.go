@@ -0,0 +1,133 @@
+package effects
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+)
+
+// Bitcrusher imitates old, low quality digital audio by reducing the bit depth and the sample rate
+// of the wrapped Streamer.
+//
+// The sample rate is reduced by holding each sample for as long as a sample at Rate would last,
+// without filtering the result, so the frequencies above Rate/2 alias as they did on old hardware.
+// The Filter removes them before the samples are held, if that's not wanted.
+//
+//	crushed := &effects.Bitcrusher[float64, beep.Stereo[float64]]{
+//		Streamer:   s,
+//		SampleRate: format.SampleRate,
+//		Bits:       8,
+//		Rate:       11025,
+//		Filter:     effects.Butterworth(effects.LowPass, 5000, 4),
+//	}
+//
+// If you're playing the Bitcrusher through the speaker, lock the speaker when modifying its fields.
+type Bitcrusher[S beep.Size, P beep.Point[S]] struct {
+	Streamer   beep.Streamer[S, P]
+	SampleRate beep.SampleRate
+
+	// Bits is the bit depth of the output, for example 8. It doesn't have to be whole, 4.5 bits
+	// sound between 4 and 5 bits. Zero leaves the bit depth unchanged.
+	Bits float64
+	// Dither adds triangular noise of one step of the reduced bit depth before rounding, which
+	// turns the distortion of quiet signals into a steady hiss.
+	Dither bool
+	// Rate is the reduced sample rate in Hertz [Hz]. Zero leaves the sample rate unchanged.
+	Rate float64
+	// Jitter between 0 and 1 randomly varies the length of the held samples by up to that fraction,
+	// like an unstable clock.
+	Jitter float64
+	// Filter are the sections of the anti-aliasing filter applied before the sample rate reduction,
+	// for example Butterworth(LowPass, Rate/2, 4). Nil means no filter.
+	Filter []Biquad
+
+	rng         *rand.Rand
+	phase       float64 // time since the current sample was taken, in samples at Rate
+	period      float64 // length of the current held sample, 1 plus jitter, in samples at Rate
+	held        []float64
+	state       [][]biquadState
+	filterCoefs []biquadCoefs
+}
+
+// Stream streams the wrapped Streamer crushed.
+func (b *Bitcrusher[S, P]) Stream(samples []P) (n int, ok bool) {
+	n, ok = b.Streamer.Stream(samples)
+	if b.rng == nil {
+		var p P
+		b.rng = rand.New(rand.NewSource(1))
+		b.held = make([]float64, p.Count())
+		b.phase, b.period = 1, 1
+	}
+	b.updateFilter()
+
+	step := 0.0
+	if b.Bits > 0 {
+		step = 2 / math.Pow(2, b.Bits)
+	}
+	advance := 1.0
+	if b.Rate > 0 && b.Rate < float64(b.SampleRate) {
+		advance = b.Rate / float64(b.SampleRate)
+	}
+
+	points.Each(samples[:n], func(ch []S) {
+		for c := range ch {
+			x := float64(ch[c])
+			for j, coefs := range b.filterCoefs {
+				x = b.state[j][c].process(coefs, x)
+			}
+			ch[c] = S(x)
+		}
+
+		// take a new sample once the held one has lasted long enough
+		if b.phase >= b.period {
+			b.phase -= b.period
+			b.period = 1 + b.Jitter*(b.rng.Float64()*2-1)
+			for c := range ch {
+				b.held[c] = b.quantize(float64(ch[c]), step)
+			}
+		}
+		b.phase += advance
+		for c := range ch {
+			ch[c] = S(b.held[c])
+		}
+	})
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (b *Bitcrusher[S, P]) Err() error {
+	return b.Streamer.Err()
+}
+
+// quantize rounds x to a multiple of step, with dither if enabled. Like an integer sample of the
+// bit depth, the result is between -1 and one step below 1, which makes 2^Bits levels. A zero step
+// means no rounding.
+func (b *Bitcrusher[S, P]) quantize(x, step float64) float64 {
+	if step == 0 {
+		return x
+	}
+	x /= step
+	if b.Dither {
+		x += b.rng.Float64() - b.rng.Float64()
+	}
+	q := math.Max(math.Ceil(-1/step), math.Min(math.Ceil(1/step)-1, math.Round(x)))
+	return q * step
+}
+
+// updateFilter recalculates the filter coefficients, keeping the state if the number of sections
+// didn't change.
+func (b *Bitcrusher[S, P]) updateFilter() {
+	if len(b.filterCoefs) != len(b.Filter) {
+		var p P
+		b.filterCoefs = make([]biquadCoefs, len(b.Filter))
+		b.state = make([][]biquadState, len(b.Filter))
+		for j := range b.state {
+			b.state[j] = make([]biquadState, p.Count())
+		}
+	}
+	for j, f := range b.Filter {
+		b.filterCoefs[j] = f.coefs(b.SampleRate)
+	}
+}
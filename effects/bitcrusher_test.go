@@ -0,0 +1,110 @@
+package effects_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+)
+
+func ramp(n int) []beep.Stereo[float64] {
+	data := make([]beep.Stereo[float64], n)
+	for i := range data {
+		x := float64(i)/float64(n)*2 - 1
+		data[i] = beep.Stereo[float64]{x, -x}
+	}
+	return data
+}
+
+func TestBitcrusherBits(t *testing.T) {
+	b := &effects.Bitcrusher[float64, beep.Stereo[float64]]{
+		Streamer:   sliceStreamer(ramp(1000)),
+		SampleRate: 44100,
+		Bits:       3,
+	}
+	levels := map[float64]bool{}
+	for i, p := range collectN(b, -1) {
+		for _, x := range p {
+			if steps := x / 0.25; steps != math.Round(steps) {
+				t.Fatalf("sample %d: %v is not a multiple of 1/4", i, x)
+			}
+			levels[x] = true
+		}
+	}
+	if len(levels) != 8 || !levels[-1] || !levels[0.75] {
+		t.Errorf("got %d levels, want 8 from -1 to 0.75", len(levels))
+	}
+}
+
+func TestBitcrusherDither(t *testing.T) {
+	// a constant between two steps averages to itself with dither and rounds without it
+	for _, dither := range []bool{false, true} {
+		b := &effects.Bitcrusher[float64, beep.Stereo[float64]]{
+			Streamer:   constant(100000, 0.3),
+			SampleRate: 44100,
+			Bits:       2,
+			Dither:     dither,
+		}
+		var sum float64
+		got := collectN(b, -1)
+		for _, p := range got {
+			sum += p[0]
+		}
+		want := 0.5
+		if dither {
+			want = 0.3
+		}
+		if mean := sum / float64(len(got)); math.Abs(mean-want) > 0.01 {
+			t.Errorf("dither %v: got mean %v, want %v", dither, mean, want)
+		}
+	}
+}
+
+func TestBitcrusherRate(t *testing.T) {
+	data := ramp(1000)
+	b := &effects.Bitcrusher[float64, beep.Stereo[float64]]{
+		Streamer:   sliceStreamer(data),
+		SampleRate: 44100,
+		Rate:       11025,
+	}
+	for i, p := range collectN(b, -1) {
+		if want := data[i-i%4]; p != want {
+			t.Fatalf("sample %d: got %v, want %v", i, p, want)
+		}
+	}
+
+	b = &effects.Bitcrusher[float64, beep.Stereo[float64]]{
+		Streamer:   sliceStreamer(data),
+		SampleRate: 44100,
+		Rate:       11025,
+		Jitter:     0.5,
+	}
+	changes := 0
+	got := collectN(b, -1)
+	for i := 1; i < len(got); i++ {
+		if got[i] != got[i-1] {
+			changes++
+		}
+	}
+	if changes < 230 || changes > 270 {
+		t.Errorf("with jitter: got %d held samples, want about 250", changes)
+	}
+}
+
+func TestBitcrusherFilter(t *testing.T) {
+	const sr = beep.SampleRate(44100)
+	level := func(filter []effects.Biquad) float64 {
+		b := &effects.Bitcrusher[float64, beep.Stereo[float64]]{
+			Streamer:   sineStreamer(sr, 9000),
+			SampleRate: sr,
+			Rate:       11025,
+			Filter:     filter,
+		}
+		l, _ := amplitude(b, sr)
+		return l
+	}
+	if raw, filtered := level(nil), level(effects.Butterworth(effects.LowPass, 4000, 8)); filtered > raw/10 {
+		t.Errorf("9 kHz held at 11025 Hz: got level %v with a filter, %v without", filtered, raw)
+	}
+}
@@ -0,0 +1,139 @@
+package effects
+
+import (
+	"math"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+)
+
+// Chorus thickens the wrapped Streamer by mixing it with a copy of itself, delayed by a time which
+// slowly moves around Delay, as if several voices sang together.
+//
+// The LFO moves the delay between Delay×(1-LFO.Depth) and Delay×(1+LFO.Depth). Typical values
+// are a Delay of 15-30 ms and an LFO of 0.5-2 Hz. A StereoPhase of 0.25 widens the sound.
+//
+// If you're playing the Chorus through the speaker, lock the speaker when modifying its fields.
+type Chorus[S beep.Size, P beep.Point[S]] struct {
+	Streamer   beep.Streamer[S, P]
+	SampleRate beep.SampleRate
+	LFO        LFO
+
+	// Delay is the delay of the copy around which the LFO moves.
+	Delay time.Duration
+	// Wet is the level of the delayed copy.
+	Wet float64
+	// Dry is the level of the original signal.
+	Dry float64
+
+	m modulatedDelay
+}
+
+// Stream streams the wrapped Streamer with chorus.
+func (c *Chorus[S, P]) Stream(samples []P) (n int, ok bool) {
+	n, ok = c.Streamer.Stream(samples)
+	modulate(&c.m, samples[:n], c.SampleRate, &c.LFO, c.Delay, 0, c.Wet, c.Dry)
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (c *Chorus[S, P]) Err() error {
+	return c.Streamer.Err()
+}
+
+// Flanger mixes the wrapped Streamer with a copy of itself, delayed by a few milliseconds at most.
+// The moving delay sweeps comb filter notches up and down the spectrum, which is heard as a
+// jet-like whoosh.
+//
+// The LFO moves the delay between Delay×(1-LFO.Depth) and Delay×(1+LFO.Depth). Typical values
+// are a Delay of 1-5 ms and an LFO of 0.1-0.5 Hz. Feedback makes the effect more pronounced.
+//
+// If you're playing the Flanger through the speaker, lock the speaker when modifying its fields.
+type Flanger[S beep.Size, P beep.Point[S]] struct {
+	Streamer   beep.Streamer[S, P]
+	SampleRate beep.SampleRate
+	LFO        LFO
+
+	// Delay is the delay of the copy around which the LFO moves.
+	Delay time.Duration
+	// Feedback between -1 and 1 is the level of the delayed copy fed back into the delay.
+	// Negative values give a hollower sound.
+	Feedback float64
+	// Wet is the level of the delayed copy.
+	Wet float64
+	// Dry is the level of the original signal.
+	Dry float64
+
+	m modulatedDelay
+}
+
+// Stream streams the wrapped Streamer with flanging.
+func (f *Flanger[S, P]) Stream(samples []P) (n int, ok bool) {
+	n, ok = f.Streamer.Stream(samples)
+	modulate(&f.m, samples[:n], f.SampleRate, &f.LFO, f.Delay, f.Feedback, f.Wet, f.Dry)
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (f *Flanger[S, P]) Err() error {
+	return f.Streamer.Err()
+}
+
+// Vibrato periodically bends the pitch of the wrapped Streamer up and down by streaming it through
+// a delay which the LFO moves between Delay×(1-LFO.Depth) and Delay×(1+LFO.Depth).
+//
+// The pitch deviation grows with the Delay, the LFO.Depth and the LFO.Rate. A Delay of 3 ms with
+// an LFO of 5 Hz and a Depth of 0.5 bends the pitch by about a quarter tone.
+//
+// If you're playing the Vibrato through the speaker, lock the speaker when modifying its fields.
+type Vibrato[S beep.Size, P beep.Point[S]] struct {
+	Streamer   beep.Streamer[S, P]
+	SampleRate beep.SampleRate
+	LFO        LFO
+
+	// Delay is the delay around which the LFO moves.
+	Delay time.Duration
+
+	m modulatedDelay
+}
+
+// Stream streams the wrapped Streamer with vibrato.
+func (v *Vibrato[S, P]) Stream(samples []P) (n int, ok bool) {
+	n, ok = v.Streamer.Stream(samples)
+	modulate(&v.m, samples[:n], v.SampleRate, &v.LFO, v.Delay, 0, 1, 0)
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (v *Vibrato[S, P]) Err() error {
+	return v.Streamer.Err()
+}
+
+// modulatedDelay holds the state shared by the effects based on a delay moved by an LFO.
+type modulatedDelay struct {
+	lines []delayLine
+}
+
+// modulate streams samples through the delay lines of m, delayed by center moved by lfo. The
+// delayed signal is fed back by feedback and mixed with the original by wet and dry.
+func modulate[S beep.Size, P beep.Point[S]](m *modulatedDelay, samples []P, sr beep.SampleRate, lfo *LFO, center time.Duration, feedback, wet, dry float64) {
+	if m.lines == nil {
+		var p P
+		m.lines = make([]delayLine, p.Count())
+	}
+	d := center.Seconds() * float64(sr)
+	for c := range m.lines {
+		m.lines[c].reserve(int(d*(1+math.Abs(lfo.Depth))) + 3)
+	}
+
+	points.Each(samples, func(ch []S) {
+		for c := range ch {
+			y := m.lines[c].read(math.Max(1, d*(1+lfo.at(c))))
+			x := float64(ch[c])
+			m.lines[c].write(undenormal(x + feedback*y))
+			ch[c] = S(wet*y + dry*x)
+		}
+		lfo.advance(sr)
+	})
+}
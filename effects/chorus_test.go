@@ -0,0 +1,68 @@
+package effects_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+)
+
+func TestChorusDelaysCopy(t *testing.T) {
+	impulse := make([]beep.Stereo[float64], 100)
+	impulse[0] = beep.Stereo[float64]{1, -1}
+	c := &effects.Chorus[float64, beep.Stereo[float64]]{
+		Streamer:   sliceStreamer(impulse),
+		SampleRate: 1000,
+		LFO:        effects.LFO{Rate: 1},
+		Delay:      20 * time.Millisecond,
+		Wet:        0.5,
+		Dry:        1,
+	}
+	got := collectN(c, -1)
+	for i, p := range got {
+		var want beep.Stereo[float64]
+		switch i {
+		case 0:
+			want = beep.Stereo[float64]{1, -1}
+		case 20:
+			want = beep.Stereo[float64]{0.5, -0.5}
+		}
+		if math.Abs(p[0]-want[0]) > 1e-9 || math.Abs(p[1]-want[1]) > 1e-9 {
+			t.Errorf("sample %d: got %v, want %v", i, p, want)
+		}
+	}
+}
+
+func TestFlangerFeedback(t *testing.T) {
+	impulse := make([]beep.Stereo[float64], 100)
+	impulse[0] = beep.Stereo[float64]{1, 1}
+	f := &effects.Flanger[float64, beep.Stereo[float64]]{
+		Streamer:   sliceStreamer(impulse),
+		SampleRate: 1000,
+		Delay:      10 * time.Millisecond,
+		Feedback:   -0.5,
+		Wet:        1,
+	}
+	got := collectN(f, -1)
+	for i, want := range map[int]float64{10: 1, 20: -0.5, 30: 0.25} {
+		if math.Abs(got[i][0]-want) > 1e-9 {
+			t.Errorf("sample %d: got %v, want %v", i, got[i][0], want)
+		}
+	}
+}
+
+func TestVibratoKeepsLevel(t *testing.T) {
+	const sr = beep.SampleRate(44100)
+	v := &effects.Vibrato[float64, beep.Stereo[float64]]{
+		Streamer:   sineStreamer(sr, 440),
+		SampleRate: sr,
+		LFO:        effects.LFO{Rate: 5, Depth: 0.5},
+		Delay:      3 * time.Millisecond,
+	}
+	l, r := amplitude(v, sr)
+	if math.Abs(l-1) > 0.01 || math.Abs(r-1) > 0.01 {
+		t.Errorf("got amplitude %v, %v, want 1", l, r)
+	}
+}
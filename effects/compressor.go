@@ -0,0 +1,337 @@
+package effects
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+)
+
+// Detection selects how a dynamics effect measures the level of its input.
+type Detection int
+
+const (
+	// Peak follows the absolute value of the samples. It reacts to transients.
+	Peak Detection = iota
+	// RMS follows the root mean square over a few milliseconds. It's closer to perceived loudness.
+	RMS
+)
+
+// rmsWindow is the time constant of the RMS detection.
+const rmsWindow = 10 * time.Millisecond
+
+// Compressor reduces the dynamic range of the wrapped Streamer. When the level of the signal rises
+// above Threshold, its gain is reduced so that the level above the threshold is divided by Ratio.
+//
+// The level is detected over all channels together, so that the stereo image doesn't move. It
+// may also be detected from a separate Sidechain Streamer, for example to make a voice push down
+// the music.
+//
+// With Lookahead, the output is delayed so that the gain is already reduced when a transient
+// arrives. Once the wrapped Streamer is drained, the delayed samples are streamed.
+//
+// Set Limit to make a brickwall limiter, which guarantees that no sample of the output exceeds
+// Threshold. NewLimiter returns a Compressor set up that way.
+//
+// If you're playing the Compressor through the speaker, lock the speaker when modifying its fields,
+// except for reading GainReduction, which is safe at any time.
+type Compressor[S beep.Size, P beep.Point[S]] struct {
+	Streamer   beep.Streamer[S, P]
+	SampleRate beep.SampleRate
+
+	// Sidechain, if not nil, is used to detect the level instead of Streamer. It should stream at
+	// least as long as Streamer; once drained, it's treated as silence.
+	Sidechain beep.Streamer[S, P]
+
+	// Threshold is the level in decibels [dB] above which the gain is reduced. 0 dB is full scale.
+	Threshold float64
+	// Ratio is how many decibels the input has to rise above the threshold for the output to rise
+	// by one decibel. Ratio of 1 means no compression, math.Inf(1) means limiting.
+	Ratio float64
+	// Knee is the width in decibels [dB] of the region around the threshold where the ratio
+	// changes gradually. Zero means a hard knee.
+	Knee float64
+	// Attack is the time it takes to reduce the gain by 63% of the way to the target. With Limit,
+	// it's the length of the ramp which reduces the gain before a peak, at most Lookahead.
+	Attack time.Duration
+	// Release is the time it takes to recover the gain by 63% of the way to the target.
+	Release time.Duration
+	// Makeup is the gain in decibels [dB] applied after compression.
+	Makeup float64
+	// Lookahead delays the output, so that the gain can be reduced before the peaks.
+	Lookahead time.Duration
+	// Detection selects Peak or RMS level detection.
+	Detection Detection
+	// Limit makes the Compressor a brickwall limiter. Ratio is treated as infinite and the gain
+	// is the lowest one needed within the Lookahead, so that it's fully reduced by the time a peak
+	// is streamed. Any sample which would still exceed Threshold, for example due to Makeup or RMS
+	// detection, is clipped.
+	Limit bool
+
+	env       float64       // smoothed gain in dB, always <= 0
+	ms        float64       // mean square for RMS detection
+	delay     []S           // lookahead delay line, channels interleaved
+	pos       int           // position in delay, in samples
+	hold      []limiterGain // lowest gains in the lookahead window, increasing
+	holdHead  int
+	holdLen   int
+	ramp      []float64 // last held gains, averaged into the attack ramp
+	rampPos   int
+	rampSum   float64
+	now       int // number of samples processed, for hold
+	side      []P
+	sideDone  bool
+	drained   bool
+	remains   int
+	reduction atomic.Uint64 // bits of the gain reduction in dB, for metering
+}
+
+// NewLimiter returns a Compressor which works as a brickwall limiter, never letting the output
+// exceed ceiling decibels [dB]. It looks ahead by 5 milliseconds and ramps the gain down over that
+// time, so that peaks are reduced smoothly instead of being clipped. The SampleRate (sr) must
+// match that of the Streamer.
+func NewLimiter[S beep.Size, P beep.Point[S]](s beep.Streamer[S, P], sr beep.SampleRate, ceiling float64) *Compressor[S, P] {
+	return &Compressor[S, P]{
+		Streamer:   s,
+		SampleRate: sr,
+		Threshold:  ceiling,
+		Ratio:      math.Inf(1),
+		Attack:     5 * time.Millisecond,
+		Release:    100 * time.Millisecond,
+		Lookahead:  5 * time.Millisecond,
+		Detection:  Peak,
+		Limit:      true,
+	}
+}
+
+// Stream streams the wrapped Streamer compressed.
+func (c *Compressor[S, P]) Stream(samples []P) (n int, ok bool) {
+	var p P
+	if look := c.Latency(); look*p.Count() != len(c.delay) {
+		c.delay = make([]S, look*p.Count())
+		c.pos = 0
+	}
+	if c.Limit {
+		look := c.Latency()
+		if len(c.hold) != look+1 {
+			c.hold = make([]limiterGain, look+1)
+			c.holdHead, c.holdLen = 0, 0
+		}
+		ramp := c.SampleRate.N(c.Attack)
+		if ramp > look {
+			ramp = look
+		}
+		if len(c.ramp) != ramp+1 {
+			c.ramp = make([]float64, ramp+1)
+			for i := range c.ramp {
+				c.ramp[i] = 1
+			}
+			c.rampPos, c.rampSum = 0, float64(len(c.ramp))
+		}
+	}
+
+	for n < len(samples) {
+		var sn int
+		if !c.drained {
+			var sok bool
+			sn, sok = c.Streamer.Stream(samples[n:])
+			if !sok {
+				c.drained = true
+				c.remains = c.Latency()
+				if c.Streamer.Err() != nil {
+					c.remains = 0
+				}
+				continue
+			}
+		} else {
+			if c.remains == 0 {
+				break
+			}
+			// flush the delay line
+			sn = len(samples) - n
+			if sn > c.remains {
+				sn = c.remains
+			}
+			for i := range samples[n : n+sn] {
+				var zero P
+				samples[n+i] = zero
+			}
+			c.remains -= sn
+		}
+		c.process(samples[n : n+sn])
+		n += sn
+	}
+
+	c.reduction.Store(math.Float64bits(-c.env))
+	return n, n > 0
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (c *Compressor[S, P]) Err() error {
+	return c.Streamer.Err()
+}
+
+// GainReduction returns the current gain reduction in decibels [dB], not counting Makeup. It's
+// safe to call from any goroutine.
+func (c *Compressor[S, P]) GainReduction() float64 {
+	return math.Float64frombits(c.reduction.Load())
+}
+
+// Latency returns the number of samples by which the output lags behind the input due to
+// Lookahead.
+func (c *Compressor[S, P]) Latency() int {
+	return c.SampleRate.N(c.Lookahead)
+}
+
+func (c *Compressor[S, P]) process(samples []P) {
+	var det []P
+	if c.Sidechain != nil {
+		if cap(c.side) < len(samples) {
+			c.side = make([]P, len(samples))
+		}
+		det = c.side[:len(samples)]
+		sn := 0
+		if !c.sideDone {
+			var sok bool
+			sn, sok = c.Sidechain.Stream(det)
+			if !sok {
+				c.sideDone = true
+			}
+		}
+		for i := sn; i < len(det); i++ {
+			var zero P
+			det[i] = zero
+		}
+	}
+
+	attack := timeCoef(c.Attack, c.SampleRate)
+	release := timeCoef(c.Release, c.SampleRate)
+	rms := timeCoef(rmsWindow, c.SampleRate)
+	makeup := math.Pow(10, c.Makeup/20)
+	ceiling := S(math.Pow(10, c.Threshold/20))
+
+	i := 0
+	points.Each(samples, func(ch []S) {
+		// detect the level before the sample is swapped with the delayed one
+		var peak float64
+		if det != nil {
+			for k := range ch {
+				peak = math.Max(peak, math.Abs(float64(det[i].Get(k))))
+			}
+		} else {
+			for _, x := range ch {
+				peak = math.Max(peak, math.Abs(float64(x)))
+			}
+		}
+		i++
+		level := peak
+		if c.Detection == RMS {
+			c.ms = rms*c.ms + (1-rms)*peak*peak
+			level = math.Sqrt(c.ms)
+		}
+
+		target := c.gainComputer(20 * math.Log10(math.Max(level, 1e-10)))
+		if c.Limit {
+			target = c.lookahead(target)
+		}
+		switch {
+		case c.Limit && target < c.env:
+			// the lookahead has already ramped the gain down
+			c.env = target
+		case target < c.env:
+			c.env = attack*c.env + (1-attack)*target
+		default:
+			c.env = release*c.env + (1-release)*target
+		}
+		gain := S(math.Pow(10, c.env/20) * makeup)
+
+		if len(c.delay) > 0 {
+			d := c.delay[c.pos*len(ch) : (c.pos+1)*len(ch)]
+			for k := range ch {
+				ch[k], d[k] = d[k], ch[k]
+			}
+			c.pos = (c.pos + 1) % (len(c.delay) / len(ch))
+		}
+
+		for k := range ch {
+			ch[k] *= gain
+			if c.Limit {
+				if ch[k] > ceiling {
+					ch[k] = ceiling
+				} else if ch[k] < -ceiling {
+					ch[k] = -ceiling
+				}
+			}
+		}
+	})
+}
+
+// limiterGain is a gain in the lookahead window of a limiter.
+type limiterGain struct {
+	gain float64 // linear
+	at   int     // sample number
+}
+
+// lookahead returns the gain in decibels for a limiter, given the target gain in decibels of the
+// sample which enters the lookahead delay line. It holds the lowest gain over the window and
+// averages the held gains over the attack ramp. Every held gain in the ramp covers the sample
+// leaving the delay line, so the average never exceeds the gain that sample needs.
+func (c *Compressor[S, P]) lookahead(target float64) float64 {
+	n := len(c.hold)
+	if c.holdLen > 0 && c.hold[c.holdHead].at <= c.now-n {
+		c.holdHead = (c.holdHead + 1) % n
+		c.holdLen--
+	}
+	gain := math.Pow(10, target/20)
+	for c.holdLen > 0 && c.hold[(c.holdHead+c.holdLen-1)%n].gain >= gain {
+		c.holdLen--
+	}
+	c.hold[(c.holdHead+c.holdLen)%n] = limiterGain{gain, c.now}
+	c.holdLen++
+	c.now++
+
+	held := c.hold[c.holdHead].gain
+	c.rampSum += held - c.ramp[c.rampPos]
+	c.ramp[c.rampPos] = held
+	c.rampPos++
+	if c.rampPos == len(c.ramp) {
+		// sum up again now and then, so that rounding errors don't accumulate
+		c.rampPos, c.rampSum = 0, 0
+		for _, g := range c.ramp {
+			c.rampSum += g
+		}
+	}
+	return 20 * math.Log10(c.rampSum/float64(len(c.ramp)))
+}
+
+// gainComputer returns the gain in decibels for the input level x in decibels, using the soft knee
+// from Giannoulis, Massberg and Reiss: "Digital Dynamic Range Compressor Design".
+func (c *Compressor[S, P]) gainComputer(x float64) float64 {
+	slope := 1.0
+	if c.Ratio > 0 {
+		slope = 1 / c.Ratio
+	}
+	if c.Limit {
+		slope = 0
+	}
+	over := x - c.Threshold
+	switch {
+	case 2*over < -c.Knee:
+		return 0
+	case c.Knee > 0 && 2*math.Abs(over) <= c.Knee:
+		d := over + c.Knee/2
+		return (slope - 1) * d * d / (2 * c.Knee)
+	default:
+		return (slope - 1) * over
+	}
+}
+
+// timeCoef returns the coefficient of a one-pole smoother with the time constant d.
+func timeCoef(d time.Duration, sr beep.SampleRate) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return math.Exp(-1 / (d.Seconds() * float64(sr)))
+}
@@ -0,0 +1,169 @@
+package effects_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+)
+
+// constant streams num samples of the value v in both channels. If num is negative, it streams
+// forever.
+func constant(num int, v float64) beep.Streamer[float64, beep.Stereo[float64]] {
+	return beep.StreamerFunc[float64, beep.Stereo[float64]](func(samples []beep.Stereo[float64]) (n int, ok bool) {
+		if num == 0 {
+			return 0, false
+		}
+		if 0 < num && num < len(samples) {
+			samples = samples[:num]
+		}
+		for i := range samples {
+			samples[i] = beep.Stereo[float64]{v, v}
+		}
+		if num > 0 {
+			num -= len(samples)
+		}
+		return len(samples), true
+	})
+}
+
+func TestCompressorStaticCurve(t *testing.T) {
+	const sr = beep.SampleRate(44100)
+	for _, tc := range []struct {
+		in, threshold, ratio, knee, makeup, want float64 // dB, except ratio
+		detection                                effects.Detection
+	}{
+		{-6, -20, 4, 0, 0, -16.5, effects.Peak},
+		{-6, -20, 4, 0, 3, -13.5, effects.RMS},
+		{-30, -20, 4, 0, 0, -30, effects.Peak},
+		{-20, -20, 2, 10, 0, -20.625, effects.Peak},
+		{-6, -20, 1, 0, 0, -6, effects.Peak},
+	} {
+		c := &effects.Compressor[float64, beep.Stereo[float64]]{
+			Streamer:   constant(-1, math.Pow(10, tc.in/20)),
+			SampleRate: sr,
+			Threshold:  tc.threshold,
+			Ratio:      tc.ratio,
+			Knee:       tc.knee,
+			Makeup:     tc.makeup,
+			Attack:     time.Millisecond,
+			Release:    50 * time.Millisecond,
+			Detection:  tc.detection,
+		}
+		buf := make([]beep.Stereo[float64], sr)
+		c.Stream(buf)
+		if got := dB(buf[len(buf)-1][0]); math.Abs(got-tc.want) > 0.01 {
+			t.Errorf("%+v: got %.2f dB, want %.2f dB", tc, got, tc.want)
+		}
+		if got, want := c.GainReduction(), tc.in+tc.makeup-tc.want; math.Abs(got-want) > 0.01 {
+			t.Errorf("%+v: got gain reduction %.2f dB, want %.2f dB", tc, got, want)
+		}
+	}
+}
+
+func TestLimiterCeiling(t *testing.T) {
+	const sr = beep.SampleRate(44100)
+	noise := beep.StreamerFunc[float64, beep.Stereo[float64]](func(samples []beep.Stereo[float64]) (n int, ok bool) {
+		for i := range samples {
+			samples[i] = beep.Stereo[float64]{rand.Float64()*4 - 2, rand.Float64()*4 - 2}
+		}
+		return len(samples), true
+	})
+	data := collectN(beep.Take[float64, beep.Stereo[float64]](int(sr), noise), -1)
+
+	l := effects.NewLimiter[float64, beep.Stereo[float64]](sliceStreamer(data), sr, -1)
+	got := collectN(l, -1)
+	if len(got) != len(data)+l.Latency() {
+		t.Fatalf("got %d samples, want %d", len(got), len(data)+l.Latency())
+	}
+	ceiling := math.Pow(10, -1.0/20)
+	for i, p := range got {
+		if math.Abs(p[0]) > ceiling || math.Abs(p[1]) > ceiling {
+			t.Fatalf("sample %d exceeds the ceiling: %v", i, p)
+		}
+	}
+}
+
+func TestCompressorSidechain(t *testing.T) {
+	const sr = beep.SampleRate(44100)
+	c := &effects.Compressor[float64, beep.Stereo[float64]]{
+		Streamer:   constant(-1, 0.1),
+		Sidechain:  constant(int(sr)/2, 1),
+		SampleRate: sr,
+		Threshold:  -20,
+		Ratio:      10,
+		Attack:     time.Millisecond,
+		Release:    10 * time.Millisecond,
+	}
+	buf := make([]beep.Stereo[float64], sr/2)
+	c.Stream(buf)
+	if got, want := dB(buf[len(buf)-1][0]), -20-18.0; math.Abs(got-want) > 0.01 {
+		t.Errorf("while sidechain is loud: got %.2f dB, want %.2f dB", got, want)
+	}
+	c.Stream(buf)
+	if got := buf[len(buf)-1][0]; math.Abs(got-0.1) > 1e-6 {
+		t.Errorf("after sidechain is drained: got %f, want 0.1", got)
+	}
+}
+
+func sliceStreamer(data []beep.Stereo[float64]) beep.Streamer[float64, beep.Stereo[float64]] {
+	pos := 0
+	return beep.StreamerFunc[float64, beep.Stereo[float64]](func(samples []beep.Stereo[float64]) (n int, ok bool) {
+		if pos >= len(data) {
+			return 0, false
+		}
+		n = copy(samples, data[pos:])
+		pos += n
+		return n, true
+	})
+}
+
+// collectN drains s and returns at most max samples it streamed. If max is negative, there's no
+// limit.
+func collectN(s beep.Streamer[float64, beep.Stereo[float64]], max int) []beep.Stereo[float64] {
+	var out []beep.Stereo[float64]
+	buf := make([]beep.Stereo[float64], 479)
+	for max < 0 || len(out) < max {
+		n, ok := s.Stream(buf)
+		if !ok {
+			break
+		}
+		out = append(out, buf[:n]...)
+	}
+	return out
+}
+
+func TestLimiterStep(t *testing.T) {
+	const sr = beep.SampleRate(44100)
+	// a 0 dBFS step, alternating with a lower sample which must not be clipped
+	data := make([]beep.Stereo[float64], sr/10)
+	for i := len(data) / 2; i < len(data); i++ {
+		v := 1.0
+		if i%2 == 1 {
+			v = 0.9
+		}
+		data[i] = beep.Stereo[float64]{v, -v}
+	}
+
+	l := effects.NewLimiter[float64, beep.Stereo[float64]](sliceStreamer(data), sr, -6)
+	got := collectN(l, -1)[l.Latency():]
+	ceiling := math.Pow(10, -6.0/20)
+	clipped := 0
+	for i, p := range got {
+		if math.Abs(p[0]) > ceiling || math.Abs(p[1]) > ceiling {
+			t.Fatalf("sample %d exceeds the ceiling: %v", i, p)
+		}
+		if data[i][0] == 0.9 && p[0] > ceiling*(1-1e-9) {
+			clipped++
+		}
+	}
+	if clipped > 0 {
+		t.Errorf("%d samples clipped", clipped)
+	}
+	if last := got[len(got)-2][0]; math.Abs(dB(last)+6) > 0.01 {
+		t.Errorf("got %.2f dB after the step, want -6 dB", dB(last))
+	}
+}
@@ -0,0 +1,239 @@
+package effects
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+	"github.com/faiface/beep/spectrum"
+)
+
+// Convolver convolves a Streamer with an impulse response, such as a recording of a room, a hall or
+// a guitar cabinet, which makes the Streamer sound as if it was played there.
+//
+// The convolution is computed with uniformly partitioned FFT convolution: the impulse response is
+// split into partitions of equal length, so the cost per sample grows only slowly with the length
+// of the impulse response and the latency is set by the partition length alone.
+//
+// After the wrapped Streamer is drained, Convolver streams the tail of the convolution, which is as
+// long as the impulse response.
+//
+// If you're playing the Convolver through the speaker, lock the speaker when modifying its fields.
+type Convolver[S beep.Size, P beep.Point[S]] struct {
+	// Wet is the level of the convolved signal.
+	Wet float64
+	// Dry is the level of the original signal.
+	Dry float64
+
+	r    *beep.Reblocker[S, P]
+	in   *convolverInput[S, P]
+	fft  *spectrum.FFT[float64]
+	ir   [][][]complex128 // spectra of the partitions of the impulse response, per channel
+	fdl  [][][]complex128 // frequency domain delay line of the input spectra, per channel
+	head int              // newest spectrum in fdl
+	buf  [][]float64      // previous and current block of the input, per channel
+	acc  []complex128
+	out  []float64
+}
+
+// NewConvolver returns a Convolver which convolves s with the impulse response ir, read from ir
+// until it's drained. The SampleRate (sr) must match that of s. The format of ir, as returned by
+// the decoder it comes from, tells its sample rate and number of channels:
+//
+//	f, _ := os.Open("hall.wav")
+//	ir, irFormat, err := wav.Decode[float64, beep.Stereo[float64]](f)
+//	// ...
+//	verb, err := effects.NewConvolver(s, format.SampleRate, ir, irFormat, 512)
+//
+// If the sample rate of ir differs from sr, ir is resampled. A mono impulse response is applied
+// to all channels, otherwise each channel of s is convolved with the same channel of ir.
+//
+// The partition is the length of the partitions in samples, which must be a power of two. Shorter
+// partitions mean less latency and more CPU usage. If the partition is not a power of two,
+// NewConvolver panics.
+//
+// The Convolver starts with Wet of 1 and Dry of 0. NewConvolver returns an error if ir is empty or
+// fails.
+func NewConvolver[S beep.Size, P beep.Point[S]](s beep.Streamer[S, P], sr beep.SampleRate, ir beep.Streamer[S, P], irFormat beep.Format[S, P], partition int) (*Convolver[S, P], error) {
+	if partition < 1 || partition&(partition-1) != 0 {
+		panic(fmt.Errorf("effects: convolver: partition not a power of two: %d", partition))
+	}
+
+	var p P
+	irChannels := p.Count()
+	if irFormat.NumChannels == 1 {
+		irChannels = 1
+	}
+	h, err := readImpulseResponse(ir, irFormat.SampleRate, sr, irChannels)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Convolver[S, P]{
+		Wet: 1,
+		fft: spectrum.NewFFT[float64](2 * partition),
+		in:  &convolverInput[S, P]{s: s, tail: len(h[0]) - 1},
+		acc: make([]complex128, partition+1),
+		out: make([]float64, 2*partition),
+	}
+
+	// split the impulse response into partitions and transform them
+	parts := (len(h[0]) + partition - 1) / partition
+	seg := make([]float64, 2*partition)
+	c.ir = make([][][]complex128, irChannels)
+	for ch := range c.ir {
+		c.ir[ch] = make([][]complex128, parts)
+		for k := range c.ir[ch] {
+			for i := range seg {
+				seg[i] = 0
+			}
+			end := (k + 1) * partition
+			if end > len(h[ch]) {
+				end = len(h[ch])
+			}
+			copy(seg, h[ch][k*partition:end])
+			c.ir[ch][k] = make([]complex128, partition+1)
+			c.fft.Forward(c.ir[ch][k], seg)
+		}
+	}
+
+	c.fdl = make([][][]complex128, p.Count())
+	c.buf = make([][]float64, p.Count())
+	for ch := range c.fdl {
+		c.fdl[ch] = make([][]complex128, parts)
+		for k := range c.fdl[ch] {
+			c.fdl[ch][k] = make([]complex128, partition+1)
+		}
+		c.buf[ch] = make([]float64, 2*partition)
+	}
+
+	c.r = beep.Reblock[S, P](partition, c.in, c.process)
+	return c, nil
+}
+
+// Stream streams the wrapped Streamer convolved with the impulse response.
+func (c *Convolver[S, P]) Stream(samples []P) (n int, ok bool) {
+	return c.r.Stream(samples)
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (c *Convolver[S, P]) Err() error {
+	return c.r.Err()
+}
+
+// Latency returns the number of samples by which the output lags behind the input.
+func (c *Convolver[S, P]) Latency() int {
+	return c.r.Latency()
+}
+
+// process convolves one partition of the input using overlap-save.
+func (c *Convolver[S, P]) process(block []P) {
+	size := len(block)
+	parts := len(c.fdl[0])
+	c.head = (c.head + 1) % parts
+
+	for ch := range c.buf {
+		copy(c.buf[ch], c.buf[ch][size:])
+	}
+	i := 0
+	points.Each(block, func(x []S) {
+		for ch := range x {
+			c.buf[ch][size+i] = float64(x[ch])
+		}
+		i++
+	})
+
+	for ch := range c.buf {
+		h := c.ir[0]
+		if ch < len(c.ir) {
+			h = c.ir[ch]
+		}
+		c.fft.Forward(c.fdl[ch][c.head], c.buf[ch])
+		for j := range c.acc {
+			c.acc[j] = 0
+		}
+		for k := range h {
+			x := c.fdl[ch][(c.head-k+parts)%parts]
+			for j, y := range h[k] {
+				c.acc[j] += x[j] * y
+			}
+		}
+		// the first half is corrupted by circular convolution, the second half is valid
+		c.fft.Inverse(c.out, c.acc)
+		i := 0
+		points.Each(block, func(x []S) {
+			x[ch] = S(c.Wet*c.out[size+i] + c.Dry*float64(x[ch]))
+			i++
+		})
+	}
+}
+
+// readImpulseResponse reads the channels of the impulse response from ir, resampling it from
+// the sample rate old to new.
+func readImpulseResponse[S beep.Size, P beep.Point[S]](ir beep.Streamer[S, P], old, new beep.SampleRate, channels int) ([][]float64, error) {
+	scale := 1.0
+	if old != new && old > 0 {
+		ir = beep.Resample(6, old, new, ir)
+		// keep the level of the response, which would otherwise change with the number of samples
+		scale = float64(old) / float64(new)
+	}
+
+	h := make([][]float64, channels)
+	buf := make([]P, 512)
+	for {
+		n, ok := ir.Stream(buf)
+		if !ok {
+			break
+		}
+		points.Each(buf[:n], func(x []S) {
+			for ch := range h {
+				h[ch] = append(h[ch], float64(x[ch])*scale)
+			}
+		})
+	}
+	if err := ir.Err(); err != nil {
+		return nil, fmt.Errorf("effects: convolver: impulse response: %w", err)
+	}
+	if len(h[0]) == 0 {
+		return nil, errors.New("effects: convolver: empty impulse response")
+	}
+	return h, nil
+}
+
+// convolverInput streams s followed by tail samples of silence, which flush the tail of the
+// convolution. There's no tail if s fails.
+type convolverInput[S beep.Size, P beep.Point[S]] struct {
+	s       beep.Streamer[S, P]
+	tail    int
+	drained bool
+}
+
+func (c *convolverInput[S, P]) Stream(samples []P) (n int, ok bool) {
+	if !c.drained {
+		n, ok = c.s.Stream(samples)
+		if ok {
+			return n, ok
+		}
+		c.drained = true
+		if c.s.Err() != nil {
+			c.tail = 0
+		}
+	}
+	if c.tail == 0 {
+		return 0, false
+	}
+	if len(samples) > c.tail {
+		samples = samples[:c.tail]
+	}
+	for i := range samples {
+		var zero P
+		samples[i] = zero
+	}
+	c.tail -= len(samples)
+	return len(samples), true
+}
+
+func (c *convolverInput[S, P]) Err() error {
+	return c.s.Err()
+}
@@ -0,0 +1,96 @@
+package effects_test
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+)
+
+func TestConvolverMatchesDirectConvolution(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	in := make([]beep.Stereo[float64], 1000)
+	for i := range in {
+		in[i] = beep.Stereo[float64]{rng.Float64()*2 - 1, rng.Float64()*2 - 1}
+	}
+	ir := make([]beep.Stereo[float64], 300)
+	for i := range ir {
+		ir[i] = beep.Stereo[float64]{rng.Float64() - 0.5, rng.Float64() - 0.5}
+	}
+
+	for _, channels := range []int{1, 2} {
+		for _, partition := range []int{1, 16, 64, 512} {
+			format := beep.Format[float64, beep.Stereo[float64]]{SampleRate: 44100, NumChannels: channels, Precision: 2}
+			c, err := effects.NewConvolver(sliceStreamer(in), 44100, sliceStreamer(ir), format, partition)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := collectN(c, -1)
+			lat := c.Latency()
+			if want := lat + len(in) + len(ir) - 1; len(got) != want {
+				t.Fatalf("channels %d, partition %d: got %d samples, want %d", channels, partition, len(got), want)
+			}
+			for i := 0; i < len(in)+len(ir)-1; i++ {
+				for ch := 0; ch < 2; ch++ {
+					hch := ch
+					if channels == 1 {
+						hch = 0
+					}
+					var want float64
+					for j := range ir {
+						if k := i - j; k >= 0 && k < len(in) {
+							want += in[k][ch] * ir[j][hch]
+						}
+					}
+					if g := got[lat+i][ch]; math.Abs(g-want) > 1e-9 {
+						t.Fatalf("channels %d, partition %d: sample %d channel %d: got %v, want %v", channels, partition, i, ch, g, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestConvolverResamplesImpulseResponse(t *testing.T) {
+	// a response recorded at half the rate must keep the level of the signal
+	ir := make([]beep.Stereo[float64], 200)
+	var sum float64
+	for i := range ir {
+		x := 0.1 * math.Exp(-float64(i)/20)
+		ir[i] = beep.Stereo[float64]{x, x}
+		sum += x
+	}
+	format := beep.Format[float64, beep.Stereo[float64]]{SampleRate: 22050, NumChannels: 1, Precision: 2}
+	c, err := effects.NewConvolver(constant(4096, 0.5), 44100, sliceStreamer(ir), format, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := collectN(c, -1)
+	if level, want := got[2048][0], 0.5*sum; math.Abs(level-want) > 0.02*want {
+		t.Errorf("got level %v, want %v", level, want)
+	}
+}
+
+func TestConvolverErrors(t *testing.T) {
+	format := beep.Format[float64, beep.Stereo[float64]]{SampleRate: 44100, NumChannels: 2, Precision: 2}
+	if _, err := effects.NewConvolver(constant(10, 1), 44100, sliceStreamer(nil), format, 64); err == nil {
+		t.Error("expected an error for an empty impulse response")
+	}
+
+	fail := errors.New("fail")
+	bad := beep.StreamerFunc[float64, beep.Stereo[float64]](func(samples []beep.Stereo[float64]) (int, bool) { return 0, false })
+	_, err := effects.NewConvolver[float64, beep.Stereo[float64]](constant(10, 1), 44100, errStreamer{bad, fail}, format, 64)
+	if !errors.Is(err, fail) {
+		t.Errorf("got error %v, want %v", err, fail)
+	}
+}
+
+type errStreamer struct {
+	beep.Streamer[float64, beep.Stereo[float64]]
+	err error
+}
+
+func (e errStreamer) Err() error { return e.err }
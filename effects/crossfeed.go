@@ -0,0 +1,139 @@
+package effects
+
+import (
+	"math"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+)
+
+// CrossfeedPreset are the settings of a Crossfeed.
+type CrossfeedPreset struct {
+	// Cutoff is the frequency in Hertz [Hz] of the low-pass filter of the crossfed signal. The head
+	// shadows the higher frequencies from the far ear. Zero disables the crossfeed.
+	Cutoff float64
+	// Feed is how many decibels [dB] the crossfed signal is quieter than the direct signal at low
+	// frequencies, for example 4.5. Lower values mix more.
+	Feed float64
+	// Delay delays the crossfed signal further. The low-pass filter already delays it by about
+	// the time the sound takes around the head, so it's usually zero. Longer delays color centered
+	// sounds like a comb filter.
+	Delay time.Duration
+}
+
+var (
+	// BauerCrossfeed is the default setting of bs2b, the Bauer stereophonic-to-binaural DSP, which
+	// is close to a pair of speakers in a room.
+	BauerCrossfeed = CrossfeedPreset{Cutoff: 700, Feed: 4.5}
+	// ChuMoyCrossfeed imitates the crossfeed circuit of Chu Moy's headphone amplifier, which mixes
+	// the channels less.
+	ChuMoyCrossfeed = CrossfeedPreset{Cutoff: 700, Feed: 6}
+	// JanMeierCrossfeed imitates the crossfeed of Jan Meier's headphone amplifiers, a subtle
+	// setting which keeps most of the stereo image.
+	JanMeierCrossfeed = CrossfeedPreset{Cutoff: 650, Feed: 9.5}
+)
+
+// Crossfeed mixes a low-passed and delayed part of the left and the right channel of the wrapped
+// Streamer into the other one, the way each ear hears both speakers in a room. On headphones,
+// stereo recordings with instruments panned hard to one side sound unnatural and are tiring to
+// listen to without it.
+//
+// The filters follow the Bauer stereophonic-to-binaural DSP (bs2b): the direct signal is boosted
+// at high frequencies and the output is scaled, which makes up for most of the bass added by the
+// crossfeed, so centered sounds change by less than 2 dB. Only the first two channels are
+// affected.
+//
+//	cf := &effects.Crossfeed[float64, beep.Stereo[float64]]{
+//		Streamer:        s,
+//		SampleRate:      format.SampleRate,
+//		CrossfeedPreset: effects.BauerCrossfeed,
+//	}
+//
+// If you're playing the Crossfeed through the speaker, lock the speaker when modifying its fields.
+type Crossfeed[S beep.Size, P beep.Point[S]] struct {
+	Streamer   beep.Streamer[S, P]
+	SampleRate beep.SampleRate
+	CrossfeedPreset
+
+	at    CrossfeedPreset // settings for which the coefficients were calculated
+	srAt  beep.SampleRate
+	c     crossfeedCoefs
+	lo    [2]float64 // states of the low-pass filters of the crossfed signal
+	hi    [2]float64 // states of the high-boost filters of the direct signal
+	in    [2]float64 // previous input
+	lines [2]delayLine
+}
+
+type crossfeedCoefs struct {
+	a0Lo, b1Lo       float64
+	a0Hi, a1Hi, b1Hi float64
+	gain             float64
+	delay            float64 // in samples
+}
+
+// Stream streams the wrapped Streamer with crossfeed.
+func (c *Crossfeed[S, P]) Stream(samples []P) (n int, ok bool) {
+	n, ok = c.Streamer.Stream(samples)
+	if c.CrossfeedPreset != c.at || c.SampleRate != c.srAt || c.c.gain == 0 {
+		c.at, c.srAt = c.CrossfeedPreset, c.SampleRate
+		c.c = c.coefs()
+		for i := range c.lines {
+			c.lines[i].reserve(int(c.c.delay) + 4)
+		}
+	}
+	if c.Cutoff <= 0 {
+		return n, ok
+	}
+
+	k := c.c
+	points.Each(samples[:n], func(ch []S) {
+		if len(ch) < 2 {
+			return
+		}
+		var direct, cross [2]float64
+		for i := range direct {
+			x := float64(ch[i])
+			c.lo[i] = undenormal(k.a0Lo*x + k.b1Lo*c.lo[i])
+			c.hi[i] = undenormal(k.a0Hi*x + k.a1Hi*c.in[i] + k.b1Hi*c.hi[i])
+			c.in[i] = x
+			direct[i], cross[i] = c.hi[i], c.lo[i]
+			if k.delay > 0 {
+				c.lines[i].write(c.lo[i])
+				cross[i] = c.lines[i].read(k.delay + 1)
+			}
+		}
+		ch[0] = S((direct[0] + cross[1]) * k.gain)
+		ch[1] = S((direct[1] + cross[0]) * k.gain)
+	})
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (c *Crossfeed[S, P]) Err() error {
+	return c.Streamer.Err()
+}
+
+// coefs calculates the filter coefficients as bs2b does.
+func (c *Crossfeed[S, P]) coefs() crossfeedCoefs {
+	if c.Cutoff <= 0 {
+		return crossfeedCoefs{gain: 1}
+	}
+	sr := float64(c.SampleRate)
+	gbLo := -c.Feed*5/6 - 3
+	gbHi := c.Feed/6 - 3
+	gLo := math.Pow(10, gbLo/20)
+	gHi := 1 - math.Pow(10, gbHi/20)
+	cutoffHi := c.Cutoff * math.Pow(2, (gbLo-20*math.Log10(gHi))/12)
+
+	var k crossfeedCoefs
+	x := math.Exp(-2 * math.Pi * c.Cutoff / sr)
+	k.b1Lo, k.a0Lo = x, gLo*(1-x)
+	x = math.Exp(-2 * math.Pi * cutoffHi / sr)
+	k.b1Hi, k.a0Hi, k.a1Hi = x, 1-gHi*(1-x), -x
+	k.gain = 1 / (1 - gHi + gLo)
+	if c.Delay > 0 {
+		k.delay = c.Delay.Seconds() * sr
+	}
+	return k
+}
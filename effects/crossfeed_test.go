@@ -0,0 +1,67 @@
+package effects_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+)
+
+func TestCrossfeedPresets(t *testing.T) {
+	const sr = 44100
+	for _, preset := range []effects.CrossfeedPreset{effects.BauerCrossfeed, effects.ChuMoyCrossfeed, effects.JanMeierCrossfeed} {
+		// at low frequencies, the crossfed signal is Feed below the direct one
+		cf := &effects.Crossfeed[float64, beep.Stereo[float64]]{Streamer: leftOnly(sineStreamer(sr, 30)), SampleRate: sr, CrossfeedPreset: preset}
+		l, r := amplitude(cf, sr)
+		if got := 20 * math.Log10(r/l); math.Abs(got+preset.Feed) > 0.2 {
+			t.Errorf("%+v: got %.2f dB at 30 Hz, want %.2f dB", preset, got, -preset.Feed)
+		}
+
+		// at high frequencies, the head shadows the far ear
+		cf = &effects.Crossfeed[float64, beep.Stereo[float64]]{Streamer: leftOnly(sineStreamer(sr, 8000)), SampleRate: sr, CrossfeedPreset: preset}
+		l, r = amplitude(cf, sr)
+		if got := 20 * math.Log10(r/l); got > -preset.Feed-15 {
+			t.Errorf("%+v: got %.2f dB at 8 kHz, want much less", preset, got)
+		}
+	}
+}
+
+func TestCrossfeedKeepsMonoLevel(t *testing.T) {
+	const sr = 44100
+	for _, freq := range []float64{30, 1000, 8000} {
+		cf := &effects.Crossfeed[float64, beep.Stereo[float64]]{Streamer: sineStreamer(sr, freq), SampleRate: sr, CrossfeedPreset: effects.BauerCrossfeed}
+		l, r := amplitude(cf, sr)
+		if math.Abs(20*math.Log10(l)) > 2 || math.Abs(l-r) > 1e-9 {
+			t.Errorf("%v Hz: got the amplitudes %v %v, want about 1", freq, l, r)
+		}
+	}
+}
+
+func TestCrossfeedDelay(t *testing.T) {
+	const sr = 44100
+	data := make([]beep.Stereo[float64], 64)
+	data[0] = beep.Stereo[float64]{1, 0}
+	preset := effects.CrossfeedPreset{Cutoff: 700, Feed: 4.5, Delay: beep.SampleRate(sr).D(10)}
+	cf := &effects.Crossfeed[float64, beep.Stereo[float64]]{Streamer: sliceStreamer(data), SampleRate: sr, CrossfeedPreset: preset}
+	out := collectN(cf, -1)
+	for i := 0; i < 10; i++ {
+		if math.Abs(out[i][1]) > 1e-5 {
+			t.Errorf("sample %d: got %v in the right channel before the delay", i, out[i][1])
+		}
+	}
+	if out[10][1] <= 0 {
+		t.Errorf("got %v in the right channel after the delay", out[10][1])
+	}
+}
+
+func TestCrossfeedDisabled(t *testing.T) {
+	data := []beep.Stereo[float64]{{1, 0}, {0.5, -0.5}}
+	cf := &effects.Crossfeed[float64, beep.Stereo[float64]]{Streamer: sliceStreamer(append([]beep.Stereo[float64](nil), data...)), SampleRate: 44100}
+	out := collectN(cf, -1)
+	for i := range data {
+		if out[i] != data[i] {
+			t.Errorf("sample %d: got %v, want %v", i, out[i], data[i])
+		}
+	}
+}
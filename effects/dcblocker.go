@@ -0,0 +1,149 @@
+package effects
+
+import (
+	"math"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+)
+
+const (
+	// dcBlockerCutoff is the default cutoff frequency of DCBlocker in Hertz [Hz].
+	dcBlockerCutoff = 10
+	// subsonicCutoff is the default cutoff frequency of SubsonicFilter in Hertz [Hz].
+	subsonicCutoff = 20
+	// subsonicOrder is the default order of SubsonicFilter.
+	subsonicOrder = 4
+)
+
+// DCBlocker removes the DC offset of the wrapped Streamer, which wastes headroom and makes mixes
+// clip early. Decoded 8-bit audio and asymmetric waveforms often carry some.
+//
+// It's a first-order high-pass filter, which costs a multiplication and two additions per sample
+// and channel, cheap enough to run on every voice. For a steeper slope, use SubsonicFilter.
+//
+// If you're playing the DCBlocker through the speaker, lock the speaker when modifying its fields.
+type DCBlocker[S beep.Size, P beep.Point[S]] struct {
+	Streamer   beep.Streamer[S, P]
+	SampleRate beep.SampleRate
+	// Cutoff is the frequency in Hertz [Hz] below which the signal is attenuated. Zero means 10 Hz.
+	Cutoff float64
+
+	state []dcBlockerState
+}
+
+// Stream streams the wrapped Streamer without DC offset.
+func (d *DCBlocker[S, P]) Stream(samples []P) (n int, ok bool) {
+	n, ok = d.Streamer.Stream(samples)
+	if d.state == nil {
+		var p P
+		d.state = make([]dcBlockerState, p.Count())
+	}
+	cutoff := d.Cutoff
+	if cutoff <= 0 {
+		cutoff = dcBlockerCutoff
+	}
+	r := dcBlockerCoef(cutoff, d.SampleRate)
+	points.Each(samples[:n], func(ch []S) {
+		for c := range ch {
+			ch[c] = S(d.state[c].process(r, float64(ch[c])))
+		}
+	})
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (d *DCBlocker[S, P]) Err() error {
+	return d.Streamer.Err()
+}
+
+// dcBlockerState is the state of a first-order DC blocking high-pass filter of a single channel.
+type dcBlockerState struct {
+	x1, y1 float64 // previous input and output
+}
+
+// dcBlockerCoef returns the pole of the DC blocking filter with the cutoff frequency.
+func dcBlockerCoef(cutoff float64, sr beep.SampleRate) float64 {
+	return math.Exp(-2 * math.Pi * cutoff / float64(sr))
+}
+
+func (s *dcBlockerState) process(r, x float64) float64 {
+	y := x - s.x1 + r*s.y1
+	s.x1, s.y1 = x, undenormal(y)
+	return y
+}
+
+// SubsonicFilter removes the DC offset and the inaudible rumble below Cutoff from the wrapped
+// Streamer, which eat headroom and move speaker cones without making a sound.
+//
+// It's a Butterworth high-pass filter, which rolls off by 6 dB per octave for each order, so it's
+// steeper than DCBlocker, but costs more.
+//
+// If you're playing the SubsonicFilter through the speaker, lock the speaker when modifying its
+// fields.
+type SubsonicFilter[S beep.Size, P beep.Point[S]] struct {
+	Streamer   beep.Streamer[S, P]
+	SampleRate beep.SampleRate
+	// Cutoff is the frequency in Hertz [Hz] below which the signal is attenuated. Zero means 20 Hz.
+	Cutoff float64
+	// Order is the order of the filter. Zero means 4, a slope of 24 dB per octave. Odd orders are
+	// rounded up.
+	Order int
+
+	cutoffAt float64 // Cutoff for which coefs were calculated
+	orderAt  int
+	srAt     beep.SampleRate
+	coefs    []biquadCoefs
+	state    [][]biquadState // per section, per channel
+}
+
+// Stream streams the wrapped Streamer without the subsonic frequencies.
+func (f *SubsonicFilter[S, P]) Stream(samples []P) (n int, ok bool) {
+	n, ok = f.Streamer.Stream(samples)
+	f.update()
+	points.Each(samples[:n], func(ch []S) {
+		for j, co := range f.coefs {
+			st := f.state[j]
+			for c := range ch {
+				ch[c] = S(st[c].process(co, float64(ch[c])))
+			}
+		}
+	})
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (f *SubsonicFilter[S, P]) Err() error {
+	return f.Streamer.Err()
+}
+
+// update recalculates the filter coefficients if the settings changed, keeping the state if the
+// number of sections didn't change.
+func (f *SubsonicFilter[S, P]) update() {
+	if f.coefs != nil && f.Cutoff == f.cutoffAt && f.Order == f.orderAt && f.SampleRate == f.srAt {
+		return
+	}
+	f.cutoffAt, f.orderAt, f.srAt = f.Cutoff, f.Order, f.SampleRate
+
+	cutoff, order := f.Cutoff, f.Order
+	if cutoff <= 0 {
+		cutoff = subsonicCutoff
+	}
+	if order <= 0 {
+		order = subsonicOrder
+	}
+	order += order % 2
+
+	sections := Butterworth(HighPass, cutoff, order)
+	if len(f.state) != len(sections) {
+		var p P
+		f.state = make([][]biquadState, len(sections))
+		for j := range f.state {
+			f.state[j] = make([]biquadState, p.Count())
+		}
+	}
+	f.coefs = make([]biquadCoefs, len(sections))
+	for j, b := range sections {
+		f.coefs[j] = b.coefs(f.SampleRate)
+	}
+}
@@ -0,0 +1,88 @@
+package effects_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+)
+
+// withOffset adds a DC offset to s.
+func withOffset(s beep.Streamer[float64, beep.Stereo[float64]], dc float64) beep.Streamer[float64, beep.Stereo[float64]] {
+	return beep.StreamerFunc[float64, beep.Stereo[float64]](func(samples []beep.Stereo[float64]) (n int, ok bool) {
+		n, ok = s.Stream(samples)
+		for i := range samples[:n] {
+			samples[i][0] += dc
+			samples[i][1] += dc
+		}
+		return n, ok
+	})
+}
+
+// mean returns the average of the left channel of the second second of s.
+func mean(s beep.Streamer[float64, beep.Stereo[float64]], sr beep.SampleRate) float64 {
+	buf := make([]beep.Stereo[float64], sr)
+	s.Stream(buf)
+	s.Stream(buf)
+	var sum float64
+	for _, p := range buf {
+		sum += p[0]
+	}
+	return sum / float64(len(buf))
+}
+
+func TestDCBlocker(t *testing.T) {
+	const sr = 44100
+	d := &effects.DCBlocker[float64, beep.Stereo[float64]]{Streamer: withOffset(sineStreamer(sr, 100), 0.25), SampleRate: sr}
+	if m := mean(d, sr); math.Abs(m) > 1e-3 {
+		t.Errorf("got the offset %v, want 0", m)
+	}
+
+	d = &effects.DCBlocker[float64, beep.Stereo[float64]]{Streamer: sineStreamer(sr, 1000), SampleRate: sr}
+	if l, r := amplitude(d, sr); math.Abs(l-1) > 1e-3 || math.Abs(r-1) > 1e-3 {
+		t.Errorf("1 kHz: got the amplitudes %v %v, want 1", l, r)
+	}
+}
+
+func TestSubsonicFilter(t *testing.T) {
+	const sr = 44100
+	f := &effects.SubsonicFilter[float64, beep.Stereo[float64]]{Streamer: withOffset(sineStreamer(sr, 1000), 0.25), SampleRate: sr}
+	if m := mean(f, sr); math.Abs(m) > 1e-3 {
+		t.Errorf("got the offset %v, want 0", m)
+	}
+
+	tests := []struct {
+		freq  float64
+		order int
+		min   float64 // dB
+		max   float64 // dB
+	}{
+		{1000, 0, -0.01, 0.01},
+		{20, 0, -3.1, -2.9},
+		{5, 0, -49, -47},   // two octaves below, 24 dB per octave
+		{5, 2, -25, -23},   // 12 dB per octave
+		{5, 3, -49, -47},   // rounded up to 4
+		{10, 8, -49, -47},  // one octave below, 48 dB per octave
+		{40, 8, -0.1, 0.1}, // flat above the cutoff
+	}
+	for _, test := range tests {
+		f := &effects.SubsonicFilter[float64, beep.Stereo[float64]]{Streamer: sineStreamer(sr, test.freq), SampleRate: sr, Order: test.order}
+		l, _ := amplitude(f, sr)
+		if db := 20 * math.Log10(l); db < test.min || db > test.max {
+			t.Errorf("%v Hz, order %d: got %.2f dB, want between %v and %v dB", test.freq, test.order, db, test.min, test.max)
+		}
+	}
+}
+
+func TestSubsonicFilterSampleRate(t *testing.T) {
+	const sr = 44100
+	f := &effects.SubsonicFilter[float64, beep.Stereo[float64]]{Streamer: sineStreamer(sr, 20), SampleRate: sr / 2}
+	f.Stream(make([]beep.Stereo[float64], 100))
+	// the filter follows the new sample rate
+	f.SampleRate = sr
+	l, _ := amplitude(f, sr)
+	if db := 20 * math.Log10(l); db < -3.1 || db > -2.9 {
+		t.Errorf("got %.2f dB at the cutoff, want -3 dB", db)
+	}
+}
@@ -0,0 +1,226 @@
+package effects
+
+import (
+	"math"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+)
+
+// delaySmoothing is the time constant with which the delay time follows changes of Delay.Time.
+const delaySmoothing = 50 * time.Millisecond
+
+// Delay repeats the wrapped Streamer after Time as echoes, which decay by Feedback each time
+// around.
+//
+// Time can be changed while streaming, for example by an LFO. The delay time glides to the new
+// value and the delayed signal is interpolated between samples, so the change is heard as a smooth
+// pitch bend instead of clicks. The delay lines grow as needed, which allocates while streaming;
+// set MaxTime to allocate them once up front.
+//
+// After the wrapped Streamer is drained, Delay keeps streaming the echoes until they decay to
+// silence.
+//
+//	echo := &effects.Delay[float64, beep.Stereo[float64]]{
+//		Streamer:   s,
+//		SampleRate: format.SampleRate,
+//		Time:       effects.TempoSync(120, 3.0/16), // dotted eighth at 120 BPM
+//		Feedback:   0.4,
+//		Cutoff:     4000,
+//		Wet:        0.5,
+//		Dry:        1,
+//		PingPong:   true,
+//	}
+//
+// If you're playing the Delay through the speaker, lock the speaker when modifying its fields.
+type Delay[S beep.Size, P beep.Point[S]] struct {
+	Streamer   beep.Streamer[S, P]
+	SampleRate beep.SampleRate
+
+	// Time is the delay of the first echo.
+	Time time.Duration
+	// TimeSamples, if not zero, is the delay of the first echo in samples, and is used instead of
+	// Time. It may be fractional.
+	TimeSamples float64
+	// MaxTime is the longest delay time expected while streaming. The delay lines are allocated
+	// for it when streaming starts, so that Stream doesn't allocate unless the delay time grows
+	// beyond MaxTime.
+	MaxTime time.Duration
+	// Feedback between 0 and 1 is the level of each echo relative to the previous one. Values of
+	// 1 and more make the echoes never decay.
+	Feedback float64
+	// Cutoff is the frequency in Hertz [Hz] of a low-pass filter in the feedback path, which makes
+	// each echo duller than the previous one, like a tape delay. Zero disables the filter.
+	Cutoff float64
+	// Wet is the level of the echoes.
+	Wet float64
+	// Dry is the level of the original signal.
+	Dry float64
+	// PingPong makes the echoes alternate between the left and the right channel. It only affects
+	// the first two channels.
+	PingPong bool
+
+	lines   []delayLine
+	echo    []float64 // echoes of the current sample
+	lp      []float64 // states of the feedback low-pass filters
+	time    float64   // smoothed delay time in samples
+	drained bool
+	quiet   int // consecutive samples of silent output after the Streamer is drained
+}
+
+// TempoSync returns the duration of a note at the tempo of bpm beats per minute, where a beat is a
+// quarter note. The note is a fraction of a whole note, for example 1.0/4 for a quarter note,
+// 1.0/8 for an eighth note, 3.0/16 for a dotted eighth note or 1.0/12 for an eighth note triplet.
+func TempoSync(bpm, note float64) time.Duration {
+	return time.Duration(note * 4 * 60 / bpm * float64(time.Second))
+}
+
+// Stream streams the wrapped Streamer with echoes.
+func (d *Delay[S, P]) Stream(samples []P) (n int, ok bool) {
+	if d.lines == nil {
+		var p P
+		d.lines = make([]delayLine, p.Count())
+		d.echo = make([]float64, p.Count())
+		d.lp = make([]float64, p.Count())
+		d.time = d.target()
+		for c := range d.lines {
+			d.lines[c].reserve(int(math.Max(d.time, d.MaxTime.Seconds()*float64(d.SampleRate))) + 2)
+		}
+	}
+
+	for n < len(samples) {
+		if !d.drained {
+			sn, sok := d.Streamer.Stream(samples[n:])
+			if !sok {
+				d.drained = true
+				if d.Streamer.Err() != nil {
+					// no echoes on error
+					d.quiet = len(d.lines[0].buf) + 1
+				}
+				continue
+			}
+			d.process(samples[n : n+sn])
+			n += sn
+			continue
+		}
+		if d.quiet > len(d.lines[0].buf) {
+			break
+		}
+		// stream the echoes sample by sample, so they stop right after they decay
+		var zero P
+		samples[n] = zero
+		d.process(samples[n : n+1])
+		var level float64
+		for c := 0; c < samples[n].Count(); c++ {
+			level = math.Max(level, math.Abs(float64(samples[n].Get(c))))
+		}
+		if level < tailSilence {
+			d.quiet++
+		} else {
+			d.quiet = 0
+		}
+		n++
+	}
+	return n, n > 0
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (d *Delay[S, P]) Err() error {
+	return d.Streamer.Err()
+}
+
+// target returns the delay time in samples, at least one sample.
+func (d *Delay[S, P]) target() float64 {
+	if d.TimeSamples != 0 {
+		return math.Max(1, d.TimeSamples)
+	}
+	return math.Max(1, d.Time.Seconds()*float64(d.SampleRate))
+}
+
+func (d *Delay[S, P]) process(samples []P) {
+	target := d.target()
+	smooth := timeCoef(delaySmoothing, d.SampleRate)
+	for c := range d.lines {
+		d.lines[c].reserve(int(math.Max(target, d.time)) + 2)
+	}
+	lowpass := 0.0
+	if d.Cutoff > 0 {
+		lowpass = math.Exp(-2 * math.Pi * d.Cutoff / float64(d.SampleRate))
+	}
+
+	points.Each(samples, func(ch []S) {
+		d.time = smooth*d.time + (1-smooth)*target
+
+		for c := range ch {
+			d.echo[c] = d.lines[c].read(d.time)
+			d.lp[c] = undenormal((1-lowpass)*d.echo[c] + lowpass*d.lp[c])
+		}
+		c := 0
+		if d.PingPong && len(ch) >= 2 {
+			// the input goes to the left channel and each echo crosses to the other channel
+			mono := (float64(ch[0]) + float64(ch[1])) / 2
+			d.lines[0].write(mono + d.Feedback*d.lp[1])
+			d.lines[1].write(d.Feedback * d.lp[0])
+			c = 2
+		}
+		for ; c < len(ch); c++ {
+			d.lines[c].write(float64(ch[c]) + d.Feedback*d.lp[c])
+		}
+
+		for c := range ch {
+			ch[c] = S(d.Wet*d.echo[c] + d.Dry*float64(ch[c]))
+		}
+	})
+}
+
+// delayLine is a circular buffer of past samples of a single channel, which can be read at
+// fractional delays.
+type delayLine struct {
+	buf []float64
+	pos int // position of the next sample to be written
+}
+
+// reserve makes the delay line hold at least n past samples, keeping the samples it holds.
+func (l *delayLine) reserve(n int) {
+	if n <= len(l.buf) {
+		return
+	}
+	size := 1
+	for size < n {
+		size *= 2
+	}
+	buf := make([]float64, size)
+	// the oldest samples go to the start of the new buffer, the newest ones right before pos
+	copy(buf[size-len(l.buf):], l.buf[l.pos:])
+	copy(buf[size-l.pos:], l.buf[:l.pos])
+	l.buf, l.pos = buf, 0
+}
+
+// write adds the newest sample to the delay line.
+func (l *delayLine) write(x float64) {
+	l.buf[l.pos] = x
+	l.pos = (l.pos + 1) % len(l.buf)
+}
+
+// at returns the sample written k samples ago, where 1 is the newest one.
+func (l *delayLine) at(k int) float64 {
+	return l.buf[((l.pos-k)%len(l.buf)+len(l.buf))%len(l.buf)]
+}
+
+// read returns the sample written d samples ago, interpolated with a cubic Hermite spline. The
+// delay d must be at least 1 and at most two less than the length of the delay line.
+func (l *delayLine) read(d float64) float64 {
+	k := int(d)
+	f := d - float64(k)
+	y0, y1, y2 := l.at(k), l.at(k+1), l.at(k+2)
+	ym := y0
+	if k > 1 {
+		ym = l.at(k - 1)
+	}
+	// Catmull-Rom spline between y0 and y1
+	c1 := (y1 - ym) / 2
+	c2 := ym - 2.5*y0 + 2*y1 - y2/2
+	c3 := (y2-ym)/2 + 1.5*(y0-y1)
+	return ((c3*f+c2)*f+c1)*f + y0
+}
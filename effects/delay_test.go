@@ -0,0 +1,166 @@
+package effects_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+)
+
+func TestTempoSync(t *testing.T) {
+	if d := effects.TempoSync(120, 1.0/4); d != 500*time.Millisecond {
+		t.Errorf("quarter note at 120 BPM: got %v, want 500ms", d)
+	}
+	if d := effects.TempoSync(120, 3.0/16); d != 375*time.Millisecond {
+		t.Errorf("dotted eighth note at 120 BPM: got %v, want 375ms", d)
+	}
+}
+
+func TestDelayEchoes(t *testing.T) {
+	const sr = beep.SampleRate(1000)
+	impulse := make([]beep.Stereo[float64], 10)
+	impulse[0] = beep.Stereo[float64]{1, 0.5}
+
+	for _, pingPong := range []bool{false, true} {
+		d := &effects.Delay[float64, beep.Stereo[float64]]{
+			Streamer:   sliceStreamer(impulse),
+			SampleRate: sr,
+			Time:       100 * time.Millisecond,
+			Feedback:   0.5,
+			Wet:        1,
+			Dry:        1,
+			PingPong:   pingPong,
+		}
+		got := collectN(d, 100000)
+		if len(got) >= 100000 {
+			t.Fatalf("ping-pong %v: the echoes don't end", pingPong)
+		}
+
+		want := map[int]beep.Stereo[float64]{
+			0:   {1, 0.5},
+			100: {1, 0.5},
+			200: {0.5, 0.25},
+			300: {0.25, 0.125},
+		}
+		if pingPong {
+			want = map[int]beep.Stereo[float64]{
+				0:   {1, 0.5},
+				100: {0.75, 0},
+				200: {0, 0.375},
+				300: {0.1875, 0},
+			}
+		}
+		for i := 0; i < 400; i++ {
+			w := want[i]
+			if math.Abs(got[i][0]-w[0]) > 1e-9 || math.Abs(got[i][1]-w[1]) > 1e-9 {
+				t.Errorf("ping-pong %v: sample %d: got %v, want %v", pingPong, i, got[i], w)
+			}
+		}
+	}
+}
+
+func TestDelayCutoffDullsEchoes(t *testing.T) {
+	const sr = beep.SampleRate(8000)
+	level := func(cutoff float64) float64 {
+		d := &effects.Delay[float64, beep.Stereo[float64]]{
+			Streamer:   sineStreamer(sr, 3000),
+			SampleRate: sr,
+			Time:       10 * time.Millisecond,
+			Feedback:   0.9,
+			Cutoff:     cutoff,
+			Wet:        1,
+		}
+		l, _ := amplitude(d, sr)
+		return l
+	}
+	if open, dull := level(0), level(500); dull >= open/2 {
+		t.Errorf("echoes of 3 kHz: %v with cutoff at 500 Hz, %v without", dull, open)
+	}
+}
+
+func TestDelayModulationIsSmooth(t *testing.T) {
+	const sr = beep.SampleRate(44100)
+	d := &effects.Delay[float64, beep.Stereo[float64]]{
+		Streamer:   sineStreamer(sr, 440),
+		SampleRate: sr,
+		Time:       20 * time.Millisecond,
+		Wet:        1,
+	}
+	buf := make([]beep.Stereo[float64], 512)
+	var prev, maxStep float64
+	for i := 0; i < 100; i++ {
+		if i%10 == 0 {
+			// jump the delay time back and forth
+			d.Time = time.Duration(5+i%20) * time.Millisecond
+		}
+		n, _ := d.Stream(buf)
+		for _, p := range buf[:n] {
+			if i > 2 {
+				maxStep = math.Max(maxStep, math.Abs(p[0]-prev))
+			}
+			prev = p[0]
+		}
+	}
+	// a 440 Hz sine never changes by more than 2π·440/44100 ≈ 0.063 between samples, a bit more
+	// when it's pitch-shifted by the gliding delay
+	if maxStep > 0.1 {
+		t.Errorf("the output jumps by %v between samples", maxStep)
+	}
+}
+
+func TestDelayError(t *testing.T) {
+	d := &effects.Delay[float64, beep.Stereo[float64]]{
+		Streamer:   errStreamer{constant(100, 1), errors.New("fail")},
+		SampleRate: 1000,
+		Time:       10 * time.Millisecond,
+		Feedback:   0.5,
+		Wet:        1,
+		Dry:        1,
+	}
+	// no echoes after an error
+	if got := collectN(d, 100000); len(got) != 100 {
+		t.Errorf("got %d samples, want 100", len(got))
+	}
+}
+
+func TestDelayTimeSamples(t *testing.T) {
+	impulse := make([]beep.Stereo[float64], 10)
+	impulse[0] = beep.Stereo[float64]{1, 1}
+	d := &effects.Delay[float64, beep.Stereo[float64]]{
+		Streamer:    sliceStreamer(impulse),
+		SampleRate:  1000,
+		Time:        100 * time.Millisecond,
+		TimeSamples: 37,
+		Wet:         1,
+	}
+	got := collectN(d, 100000)
+	if len(got) <= 37 || math.Abs(got[37][0]-1) > 1e-9 {
+		t.Errorf("expected the echo at sample 37")
+	}
+}
+
+func TestDelayMaxTimeAllocs(t *testing.T) {
+	const sr = beep.SampleRate(44100)
+	d := &effects.Delay[float64, beep.Stereo[float64]]{
+		Streamer:   constant(-1, 0.5),
+		SampleRate: sr,
+		Time:       10 * time.Millisecond,
+		MaxTime:    time.Second,
+		Feedback:   0.5,
+		Wet:        1,
+	}
+	buf := make([]beep.Stereo[float64], 512)
+	d.Stream(buf)
+	// AllocsPerRun calls the function once more to warm up
+	times := []time.Duration{20 * time.Millisecond, time.Second}
+	allocs := testing.AllocsPerRun(1, func() {
+		d.Time, times = times[0], times[1:]
+		d.Stream(buf)
+	})
+	if allocs != 0 {
+		t.Errorf("Stream allocates %v times per call", allocs)
+	}
+}
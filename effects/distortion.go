@@ -0,0 +1,185 @@
+package effects
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+)
+
+// Curve is the transfer curve of a Distortion, which maps input samples to output samples.
+type Curve int
+
+const (
+	// SoftClip rounds the peaks off with the hyperbolic tangent, like an overdriven amplifier.
+	SoftClip Curve = iota
+	// HardClip cuts everything above 1 and below -1 off, like a fuzz pedal.
+	HardClip
+	// Foldback reflects everything above 1 and below -1 back, which gives a harsh, metallic sound.
+	Foldback
+	// Tube clips the positive and the negative half of the signal differently, which adds even
+	// harmonics, like a tube amplifier.
+	Tube
+)
+
+// String returns the name of the curve.
+func (c Curve) String() string {
+	switch c {
+	case SoftClip:
+		return "SoftClip"
+	case HardClip:
+		return "HardClip"
+	case Foldback:
+		return "Foldback"
+	case Tube:
+		return "Tube"
+	}
+	return fmt.Sprintf("Curve(%d)", int(c))
+}
+
+// apply returns the output of the curve for the input x.
+func (c Curve) apply(x float64) float64 {
+	switch c {
+	case HardClip:
+		return math.Max(-1, math.Min(1, x))
+	case Foldback:
+		x = math.Mod(x-1, 4)
+		if x < 0 {
+			x += 4
+		}
+		return math.Abs(x-2) - 1
+	case Tube:
+		// a biased tanh, shifted back so that silence stays silent
+		return math.Tanh(x+tubeBias) - math.Tanh(tubeBias)
+	default:
+		return math.Tanh(x)
+	}
+}
+
+const (
+	// tubeBias is the bias of the Tube curve, which sets its asymmetry.
+	tubeBias = 0.3
+	// distortionQuality is the quality of the sinc resamplers used for oversampling.
+	distortionQuality = 12
+	// distortionDCCutoff is the cutoff in Hertz [Hz] of the high-pass filter which removes the DC
+	// offset added by asymmetric curves.
+	distortionDCCutoff = 10
+)
+
+// Distortion drives the wrapped Streamer through a waveshaping transfer curve.
+//
+// Waveshaping adds harmonics, which may lie above the Nyquist frequency and alias back as
+// inharmonic tones. To keep aliasing down, Distortion can shape the signal at a multiple of the
+// sample rate, resampled with beep.ResampleSinc, and filter the added harmonics out before
+// resampling back.
+//
+// After shaping, the DC offset added by asymmetric curves is removed, Tone filters the highs and
+// Output sets the level.
+//
+// If you're playing the Distortion through the speaker, lock the speaker when modifying its fields.
+type Distortion[S beep.Size, P beep.Point[S]] struct {
+	// Curve is the transfer curve, unless Shape is set.
+	Curve Curve
+	// Shape is a custom transfer curve. It should map 0 to 0 and keep its output between -1 and 1.
+	// It replaces Curve if not nil.
+	Shape func(x float64) float64
+	// Drive is the gain in decibels [dB] applied before the curve. More drive means more
+	// distortion.
+	Drive float64
+	// Output is the gain in decibels [dB] applied at the end.
+	Output float64
+	// Tone is the cutoff frequency in Hertz [Hz] of a low-pass filter applied after the curve,
+	// which tames the fizz of the added harmonics. Zero disables the filter.
+	Tone float64
+
+	sr     beep.SampleRate
+	s      beep.Streamer[S, P] // the whole oversampled chain
+	tone   []biquadState
+	dc     []dcBlockerState
+	toneAt float64 // Tone for which toneC was calculated
+	toneC  biquadCoefs
+}
+
+// NewDistortion returns a Distortion of s with the sample rate sr, which shapes the signal at
+// oversampling times the sample rate. The oversampling must be 1, 2, 4 or 8, otherwise
+// NewDistortion panics.
+//
+// The Distortion starts with the SoftClip curve and no gain. It propagates s's errors through Err.
+func NewDistortion[S beep.Size, P beep.Point[S]](s beep.Streamer[S, P], sr beep.SampleRate, oversampling int) *Distortion[S, P] {
+	switch oversampling {
+	case 1, 2, 4, 8:
+	default:
+		panic(fmt.Errorf("effects: distortion: invalid oversampling: %d", oversampling))
+	}
+
+	var p P
+	d := &Distortion[S, P]{
+		sr:   sr,
+		tone: make([]biquadState, p.Count()),
+		dc:   make([]dcBlockerState, p.Count()),
+	}
+	shaped := beep.Streamer[S, P](&distortionShaper[S, P]{d: d, s: s})
+	if oversampling > 1 {
+		high := sr * beep.SampleRate(oversampling)
+		up := beep.ResampleSinc(distortionQuality, sr, high, s)
+		shaped = beep.ResampleSinc[S, P](distortionQuality, high, sr, &distortionShaper[S, P]{d: d, s: up})
+	}
+	d.s = shaped
+	return d
+}
+
+// Stream streams the wrapped Streamer distorted.
+func (d *Distortion[S, P]) Stream(samples []P) (n int, ok bool) {
+	n, ok = d.s.Stream(samples)
+
+	if d.Tone > 0 && d.Tone != d.toneAt {
+		d.toneC = Biquad{Type: LowPass, Freq: d.Tone, Q: math.Sqrt2 / 2}.coefs(d.sr)
+		d.toneAt = d.Tone
+	}
+	dc := dcBlockerCoef(distortionDCCutoff, d.sr)
+	output := math.Pow(10, d.Output/20)
+
+	points.Each(samples[:n], func(ch []S) {
+		for c := range ch {
+			y := d.dc[c].process(dc, float64(ch[c]))
+			if d.Tone > 0 {
+				y = d.tone[c].process(d.toneC, y)
+			}
+			ch[c] = S(y * output)
+		}
+	})
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (d *Distortion[S, P]) Err() error {
+	return d.s.Err()
+}
+
+// distortionShaper applies the drive and the curve of a Distortion, at the oversampled rate.
+type distortionShaper[S beep.Size, P beep.Point[S]] struct {
+	d *Distortion[S, P]
+	s beep.Streamer[S, P]
+}
+
+func (ds *distortionShaper[S, P]) Stream(samples []P) (n int, ok bool) {
+	n, ok = ds.s.Stream(samples)
+	drive := math.Pow(10, ds.d.Drive/20)
+	points.Each(samples[:n], func(ch []S) {
+		for c := range ch {
+			x := float64(ch[c]) * drive
+			if ds.d.Shape != nil {
+				x = ds.d.Shape(x)
+			} else {
+				x = ds.d.Curve.apply(x)
+			}
+			ch[c] = S(x)
+		}
+	})
+	return n, ok
+}
+
+func (ds *distortionShaper[S, P]) Err() error {
+	return ds.s.Err()
+}
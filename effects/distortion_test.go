@@ -0,0 +1,94 @@
+package effects_test
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+	"github.com/faiface/beep/spectrum"
+)
+
+func TestDistortionCurves(t *testing.T) {
+	tests := []struct {
+		curve effects.Curve
+		in    float64
+		want  float64
+	}{
+		{effects.SoftClip, 0.5, math.Tanh(0.5)},
+		{effects.SoftClip, 10, math.Tanh(10)},
+		{effects.HardClip, 0.5, 0.5},
+		{effects.HardClip, -3, -1},
+		{effects.Foldback, 0.5, 0.5},
+		{effects.Foldback, 1.5, 0.5},
+		{effects.Foldback, -1.25, -0.75},
+		{effects.Foldback, 3.5, -0.5},
+		{effects.Tube, 0, 0},
+	}
+	for _, test := range tests {
+		d := effects.NewDistortion(sliceStreamer([]beep.Stereo[float64]{{test.in, test.in}}), 44100, 1)
+		d.Curve = test.curve
+		got := collectN(d, -1)
+		if len(got) != 1 || math.Abs(got[0][0]-test.want) > 1e-9 {
+			t.Errorf("%v(%v): got %v, want %v", test.curve, test.in, got, test.want)
+		}
+	}
+
+	// the tube curve is asymmetric
+	d := effects.NewDistortion(sliceStreamer([]beep.Stereo[float64]{{2, -2}}), 44100, 1)
+	d.Curve = effects.Tube
+	got := collectN(d, -1)
+	if got[0][0] <= 0 || got[0][1] >= 0 || math.Abs(got[0][0]+got[0][1]) < 0.1 {
+		t.Errorf("Tube(2), Tube(-2): got %v", got[0])
+	}
+
+	d = effects.NewDistortion(sliceStreamer([]beep.Stereo[float64]{{0.5, 0.5}}), 44100, 1)
+	d.Shape = func(x float64) float64 { return x * x }
+	d.Drive, d.Output = 20*math.Log10(2), 20*math.Log10(0.5)
+	if got := collectN(d, -1); math.Abs(got[0][0]-0.5) > 1e-9 {
+		t.Errorf("custom shape: got %v, want 0.5", got[0][0])
+	}
+}
+
+// aliasing returns the level of a hard clipped sine in decibels relative to the fundamental at the
+// frequencies which are not its harmonics.
+func aliasing(oversampling int) float64 {
+	const (
+		sr = beep.SampleRate(44100)
+		n  = 4096
+		k  = 464 // the bin of the fundamental, about 5 kHz
+	)
+	d := effects.NewDistortion(sineStreamer(sr, spectrum.BinFrequency(sr, n, k)), sr, oversampling)
+	d.Curve = effects.HardClip
+	d.Drive = 12
+	got := collectN(d, 3*n)[2*n:]
+
+	win := spectrum.BlackmanHarris(n)
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = got[i][0] * win[i]
+	}
+	fft := spectrum.NewFFT[float64](n)
+	bins := make([]complex128, fft.Bins())
+	fft.Forward(bins, x)
+
+	var harmonics, alias float64
+	for b, v := range bins {
+		power := cmplx.Abs(v) * cmplx.Abs(v)
+		// the harmonics of the fundamental fall exactly on multiples of k
+		if r := b % k; r <= 4 || r >= k-4 {
+			harmonics += power
+		} else {
+			alias += power
+		}
+	}
+	return 10 * math.Log10(alias/harmonics)
+}
+
+func TestDistortionOversamplingReducesAliasing(t *testing.T) {
+	naive, over := aliasing(1), aliasing(8)
+	if over > naive-20 {
+		t.Errorf("aliasing: %.1f dB without oversampling, %.1f dB with 8x oversampling", naive, over)
+	}
+}
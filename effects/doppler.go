@@ -1,6 +1,9 @@
 package effects
 
-import "github.com/faiface/beep"
+import (
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+)
 
 // Doppler simulates a "sound at a distance". If the sound starts at a far distance,
 // it'll take some time to reach the ears of the listener.
@@ -44,10 +47,11 @@ func (d *doppler[S, P]) Stream(samples []P) (n int, ok bool) {
 	d.space = append(d.space, make([]P, len(samples)+difference)...)
 	rn, _ := d.r.Stream(d.space[len(d.space)-len(samples)-difference:])
 	d.space = d.space[:len(d.space)-len(samples)-difference+rn]
-	for i := len(d.space) - rn; i < len(d.space); i++ {
-		d.space[i][0] /= S(distance * distance)
-		d.space[i][1] /= S(distance * distance)
-	}
+	points.Each(d.space[len(d.space)-rn:], func(ch []S) {
+		for c := range ch {
+			ch[c] /= S(distance * distance)
+		}
+	})
 
 	if len(d.space) == 0 {
 		return 0, false
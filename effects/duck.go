@@ -0,0 +1,141 @@
+package effects
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+)
+
+// Duck mixes Voice over Music, turning Music down whenever Voice is active, as radio hosts and
+// game dialogue do. Voice passes through unchanged.
+//
+// The level of Voice is followed by an envelope with Attack and Release times. While the envelope
+// is above Threshold, Music is lowered by Depth, with the same Attack and Release times.
+//
+// Duck streams as long as either Music or Voice streams. A drained Streamer is treated as silence.
+// If either of them fails, Duck stops streaming right where it failed, and Err returns the error.
+//
+// If you're playing the Duck through the speaker, lock the speaker when modifying its fields,
+// except for reading GainReduction, which is safe at any time.
+type Duck[S beep.Size, P beep.Point[S]] struct {
+	Music      beep.Streamer[S, P]
+	Voice      beep.Streamer[S, P]
+	SampleRate beep.SampleRate
+
+	// Threshold is the level of Voice in decibels [dB] above which Music is ducked.
+	Threshold float64
+	// Depth is how many decibels [dB] Music is lowered by, for example 12.
+	Depth float64
+	// Attack is the time it takes to duck Music by 63% of the way.
+	Attack time.Duration
+	// Release is the time it takes to bring Music back by 63% of the way.
+	Release time.Duration
+
+	env       float64 // envelope of the voice level, linear
+	gain      float64 // gain of the music in dB
+	voice     []P
+	musicDone bool
+	voiceDone bool
+	reduction atomic.Uint64 // bits of the gain reduction in dB, for metering
+}
+
+// Stream streams Music ducked by Voice, mixed with Voice.
+func (d *Duck[S, P]) Stream(samples []P) (n int, ok bool) {
+	if d.musicDone && d.voiceDone || d.failed() {
+		return 0, false
+	}
+	if cap(d.voice) < len(samples) {
+		d.voice = make([]P, len(samples))
+	}
+	voice := d.voice[:len(samples)]
+
+	mn := streamOrSilence(d.Music, samples, &d.musicDone)
+	vn := streamOrSilence(d.Voice, voice, &d.voiceDone)
+	n = mn
+	if vn > n {
+		n = vn
+	}
+	if d.musicDone && d.Music.Err() != nil && mn < n {
+		n = mn
+	}
+	if d.voiceDone && d.Voice.Err() != nil && vn < n {
+		n = vn
+	}
+
+	attack := timeCoef(d.Attack, d.SampleRate)
+	release := timeCoef(d.Release, d.SampleRate)
+	threshold := math.Pow(10, d.Threshold/20)
+
+	i := 0
+	points.Each(samples[:n], func(ch []S) {
+		var peak float64
+		for k := range ch {
+			peak = math.Max(peak, math.Abs(float64(voice[i].Get(k))))
+		}
+		if peak > d.env {
+			d.env = attack*d.env + (1-attack)*peak
+		} else {
+			d.env = release*d.env + (1-release)*peak
+		}
+
+		target := 0.0
+		if d.env >= threshold {
+			target = -d.Depth
+		}
+		if target < d.gain {
+			d.gain = attack*d.gain + (1-attack)*target
+		} else {
+			d.gain = release*d.gain + (1-release)*target
+		}
+
+		gain := S(math.Pow(10, d.gain/20))
+		for k := range ch {
+			ch[k] = ch[k]*gain + voice[i].Get(k)
+		}
+		i++
+	})
+
+	d.reduction.Store(math.Float64bits(-d.gain))
+	return n, n > 0
+}
+
+// Err propagates the errors of Music and Voice.
+func (d *Duck[S, P]) Err() error {
+	if err := d.Music.Err(); err != nil {
+		return err
+	}
+	return d.Voice.Err()
+}
+
+// failed reports whether Music or Voice has stopped with an error.
+func (d *Duck[S, P]) failed() bool {
+	return d.musicDone && d.Music.Err() != nil || d.voiceDone && d.Voice.Err() != nil
+}
+
+// GainReduction returns how many decibels [dB] Music is currently lowered by. It's safe to call
+// from any goroutine.
+func (d *Duck[S, P]) GainReduction() float64 {
+	return math.Float64frombits(d.reduction.Load())
+}
+
+// streamOrSilence fills samples from s, padding them with silence once s is drained, and returns
+// the number of samples s streamed. The done flag is set when s is drained.
+func streamOrSilence[S beep.Size, P beep.Point[S]](s beep.Streamer[S, P], samples []P, done *bool) int {
+	n := 0
+	for !*done && n < len(samples) {
+		sn, sok := s.Stream(samples[n:])
+		if !sok {
+			*done = true
+			break
+		}
+		n += sn
+	}
+	for i := n; i < len(samples); i++ {
+		var zero P
+		samples[i] = zero
+	}
+	return n
+}
@@ -0,0 +1,56 @@
+package effects_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+)
+
+func TestDuck(t *testing.T) {
+	const sr = beep.SampleRate(10000)
+	d := &effects.Duck[float64, beep.Stereo[float64]]{
+		Music:      constant(3*int(sr), 0.5),
+		Voice:      beep.Seq(constant(int(sr), 0), constant(int(sr), 0.2)),
+		SampleRate: sr,
+		Threshold:  -30,
+		Depth:      12,
+		Attack:     5 * time.Millisecond,
+		Release:    50 * time.Millisecond,
+	}
+	got := collectN(d, -1)
+	if len(got) != 3*int(sr) {
+		t.Fatalf("got %d samples, want %d", len(got), 3*int(sr))
+	}
+	for _, tc := range []struct {
+		at   int
+		want float64
+	}{
+		{int(sr) - 1, 0.5},
+		{2*int(sr) - 1, 0.5*math.Pow(10, -12.0/20) + 0.2},
+		{3*int(sr) - 1, 0.5},
+	} {
+		if math.Abs(got[tc.at][0]-tc.want) > 1e-3 {
+			t.Errorf("sample %d: got %f, want %f", tc.at, got[tc.at][0], tc.want)
+		}
+	}
+}
+
+func TestDuckStopsOnError(t *testing.T) {
+	fail := errors.New("fail")
+	d := &effects.Duck[float64, beep.Stereo[float64]]{
+		Music:      errStreamer{constant(1000, 0.5), fail},
+		Voice:      constant(10000, 0.2),
+		SampleRate: 10000,
+		Depth:      12,
+	}
+	if got := collectN(d, -1); len(got) != 1000 {
+		t.Errorf("got %d samples, want 1000", len(got))
+	}
+	if !errors.Is(d.Err(), fail) {
+		t.Errorf("got error %v, want %v", d.Err(), fail)
+	}
+}
@@ -2,27 +2,45 @@ package effects
 
 import (
 	"math"
+	"sync/atomic"
 
 	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
 )
 
 type (
 
+	// Equalizer is a parametric equalizer created by NewEqualizer. Its sections may be changed
+	// with SetSections while it's being streamed.
+	//
 	// This parametric equalizer is based on the GK Nilsen's post at:
 	// https://octovoid.com/2017/11/04/coding-a-parametric-equalizer-for-audio-applications/
-	equalizer[S beep.Size, P beep.Point[S]] struct {
+	Equalizer[S beep.Size, P beep.Point[S]] struct {
 		streamer beep.Streamer[S, P]
-		sections []section[S, P]
+		fs       float64
+		next     atomic.Pointer[equalizerConfig]
+		cur      []section // coefficients in effect, interpolated while ramping
+		from     []section // coefficients at the start of the ramp
+		to       []section // coefficients at the end of the ramp
+		state    [][]biquadState
+		ramp     int // position in the ramp, rampLen when not ramping
+		rampLen  int
 	}
 
-	section[S beep.Size, P beep.Point[S]] struct {
-		a, b         [2][]S
-		xPast, yPast []P
+	// section holds the coefficients for the left and the right channel.
+	section [2]biquadCoefs
+
+	// equalizerConfig is handed over from SetSections to Stream. Everything except sections is
+	// preallocated scratch space, which Stream adopts when the number of sections changes.
+	equalizerConfig struct {
+		sections  []section
+		cur, from []section
+		state     [][]biquadState
 	}
 
 	// EqualizerSections is the interfacd that is passed into NewEqualizer
 	EqualizerSections[S beep.Size, P beep.Point[S]] interface {
-		sections(fs float64) []section[S, P]
+		sections(fs float64) []section
 	}
 
 	StereoEqualizerSection[S beep.Size, P beep.Point[S]] struct {
@@ -70,25 +88,59 @@ type (
 	MonoEqualizerSections[S beep.Size, P beep.Point[S]] []MonoEqualizerSection[S, P]
 )
 
-// NewEqualizer returns a beep.Streamer that modifies the stream based on the EqualizerSection slice that is passed in.
+// equalizerRamp is the time in seconds over which the coefficients move to new values set by
+// SetSections. Jumping to the new coefficients at once causes audible clicks.
+const equalizerRamp = 0.02
+
+// NewEqualizer returns an Equalizer that modifies the stream based on the EqualizerSection slice that is passed in.
 // The SampleRate (sr) must match that of the Streamer.
-func NewEqualizer[S beep.Size, P beep.Point[S]](st beep.Streamer[S, P], sr beep.SampleRate, s EqualizerSections[S, P]) beep.Streamer[S, P] {
-	return &equalizer[S, P]{
+//
+// NewEqualizer used to return a beep.Streamer backed by an unexported type. It now returns the
+// exported *Equalizer, which is still a beep.Streamer, so that the sections can be changed with
+// SetSections. Callers which store the result in a beep.Streamer are not affected, but the result
+// now has a different static type, for example in := declarations and type switches.
+func NewEqualizer[S beep.Size, P beep.Point[S]](st beep.Streamer[S, P], sr beep.SampleRate, s EqualizerSections[S, P]) *Equalizer[S, P] {
+	e := &Equalizer[S, P]{
 		streamer: st,
-		sections: s.sections(float64(sr)),
+		fs:       float64(sr),
+		rampLen:  int(float64(sr) * equalizerRamp),
 	}
+	e.SetSections(s)
+	return e
 }
 
-func (m MonoEqualizerSections[S, P]) sections(fs S) []section[S, P] {
-	out := make([]section[S, P], len(m))
+// SetSections replaces the sections of the Equalizer. It's safe to call SetSections from another
+// goroutine while the Equalizer is being streamed.
+//
+// If the number of sections stays the same, the coefficients move smoothly to the new values
+// over a few milliseconds, keeping the filter history intact. Otherwise the new sections take
+// effect immediately with a clear history.
+func (e *Equalizer[S, P]) SetSections(s EqualizerSections[S, P]) {
+	sections := s.sections(e.fs)
+	var p P
+	cfg := &equalizerConfig{
+		sections: sections,
+		cur:      make([]section, len(sections)),
+		from:     make([]section, len(sections)),
+		state:    make([][]biquadState, len(sections)),
+	}
+	copy(cfg.cur, sections)
+	for i := range cfg.state {
+		cfg.state[i] = make([]biquadState, p.Count())
+	}
+	e.next.Store(cfg)
+}
+
+func (m MonoEqualizerSections[S, P]) sections(fs float64) []section {
+	out := make([]section, len(m))
 	for i, s := range m {
 		out[i] = s.section(fs)
 	}
 	return out
 }
 
-func (m StereoEqualizerSections[S, P]) sections(fs S) []section[S, P] {
-	out := make([]section[S, P], len(m))
+func (m StereoEqualizerSections[S, P]) sections(fs float64) []section {
+	out := make([]section, len(m))
 	for i, s := range m {
 		out[i] = s.section(fs)
 	}
@@ -96,99 +148,81 @@ func (m StereoEqualizerSections[S, P]) sections(fs S) []section[S, P] {
 }
 
 // Stream streams the wrapped Streamer modified by Equalizer.
-func (e *equalizer[S, P]) Stream(samples []P) (n int, ok bool) {
+func (e *Equalizer[S, P]) Stream(samples []P) (n int, ok bool) {
 	n, ok = e.streamer.Stream(samples)
-	for _, s := range e.sections {
-		s.apply(samples)
-	}
+	if cfg := e.next.Swap(nil); cfg != nil {
+		e.update(cfg)
+	}
+	points.Each(samples[:n], func(ch []S) {
+		if e.ramp < e.rampLen {
+			e.ramp++
+			t := float64(e.ramp) / float64(e.rampLen)
+			for i := range e.cur {
+				for c := range e.cur[i] {
+					e.cur[i][c] = e.from[i][c].lerp(e.to[i][c], t)
+				}
+			}
+		}
+		for i, s := range e.cur {
+			st := e.state[i]
+			for c := range ch {
+				// channels past the right one use the coefficients of the right one
+				k := c
+				if k > 1 {
+					k = 1
+				}
+				ch[c] = S(st[c].process(s[k], float64(ch[c])))
+			}
+		}
+	})
 	return n, ok
 }
 
 // Err propagates the wrapped Streamer's errors.
-func (e *equalizer[S, P]) Err() error {
+func (e *Equalizer[S, P]) Err() error {
 	return e.streamer.Err()
 }
 
-func (m MonoEqualizerSection[S, P]) section(fs S) section[S, P] {
-	beta := math.Tan(m.Bf/2.0*math.Pi/(float64(fs)/2.0)) *
+// update switches to the sections of cfg, either by ramping to them or by replacing the current
+// ones if their number differs.
+func (e *Equalizer[S, P]) update(cfg *equalizerConfig) {
+	if e.state == nil || len(cfg.sections) != len(e.cur) {
+		e.cur, e.from, e.to, e.state = cfg.cur, cfg.from, cfg.sections, cfg.state
+		e.ramp = e.rampLen
+		return
+	}
+	copy(e.from, e.cur)
+	e.to = cfg.sections
+	e.ramp = 0
+}
+
+func (m MonoEqualizerSection[S, P]) section(fs float64) section {
+	if m.G == m.G0 {
+		// without boost or cut, beta below is 0/0
+		c := biquadCoefs{b0: math.Pow(10.0, m.G0/20.0)}
+		return section{c, c}
+	}
+
+	beta := math.Tan(m.Bf/2.0*math.Pi/(fs/2.0)) *
 		math.Sqrt(math.Abs(math.Pow(math.Pow(10, m.GB/20.0), 2.0)-
 			math.Pow(math.Pow(10.0, m.G0/20.0), 2.0))) /
 		math.Sqrt(math.Abs(math.Pow(math.Pow(10.0, m.G/20.0), 2.0)-
 			math.Pow(math.Pow(10.0, m.GB/20.0), 2.0)))
 
-	b := []S{
-		S((math.Pow(10.0, m.G0/20.0) + math.Pow(10.0, m.G/20.0)*beta) / (1 + beta)),
-		S((-2 * math.Pow(10.0, m.G0/20.0) * math.Cos(m.F0*math.Pi/(float64(fs)/2.0))) / (1 + beta)),
-		S((math.Pow(10.0, m.G0/20) - math.Pow(10.0, m.G/20.0)*beta) / (1 + beta)),
+	c := biquadCoefs{
+		b0: (math.Pow(10.0, m.G0/20.0) + math.Pow(10.0, m.G/20.0)*beta) / (1 + beta),
+		b1: (-2 * math.Pow(10.0, m.G0/20.0) * math.Cos(m.F0*math.Pi/(fs/2.0))) / (1 + beta),
+		b2: (math.Pow(10.0, m.G0/20) - math.Pow(10.0, m.G/20.0)*beta) / (1 + beta),
+		a1: -2 * math.Cos(m.F0*math.Pi/(fs/2.0)) / (1 + beta),
+		a2: (1 - beta) / (1 + beta),
 	}
 
-	a := []S{
-		1.0,
-		S(-2 * math.Cos(m.F0*math.Pi/(float64(fs)/2.0)) / (1 + beta)),
-		S((1 - beta) / (1 + beta)),
-	}
-
-	return section[S, P]{
-		a: [2][]S{a, a},
-		b: [2][]S{b, b},
-	}
+	return section{c, c}
 }
 
-func (s StereoEqualizerSection[S, P]) section(fs S) section[S, P] {
+func (s StereoEqualizerSection[S, P]) section(fs float64) section {
 	l := s.Left.section(fs)
 	r := s.Right.section(fs)
 
-	return section[S, P]{
-		a: [2][]S{l.a[0], r.a[0]},
-		b: [2][]S{l.b[0], r.b[0]},
-	}
-}
-
-func (s *section[S, P]) apply(x []P) {
-	ord := len(s.a[0]) - 1
-	np := len(x) - 1
-
-	if np < ord {
-		x = append(x, make([]P, ord-np)...)
-		np = ord
-	}
-
-	y := make([]P, len(x))
-
-	if len(s.xPast) < len(x) {
-		s.xPast = append(s.xPast, make([]P, len(x)-len(s.xPast))...)
-	}
-
-	if len(s.yPast) < len(x) {
-		s.yPast = append(s.yPast, make([]P, len(x)-len(s.yPast))...)
-	}
-
-	for i := 0; i < len(x); i++ {
-		for j := 0; j < ord+1; j++ {
-			if i-j < 0 {
-				y[i][0] = y[i][0] + s.b[0][j]*s.xPast[len(s.xPast)-j][0]
-				y[i][1] = y[i][1] + s.b[1][j]*s.xPast[len(s.xPast)-j][1]
-			} else {
-				y[i][0] = y[i][0] + s.b[0][j]*x[i-j][0]
-				y[i][1] = y[i][1] + s.b[1][j]*x[i-j][1]
-			}
-		}
-
-		for j := 0; j < ord; j++ {
-			if i-j-1 < 0 {
-				y[i][0] = y[i][0] - s.a[0][j+1]*s.yPast[len(s.yPast)-j-1][0]
-				y[i][1] = y[i][1] - s.a[1][j+1]*s.yPast[len(s.yPast)-j-1][1]
-			} else {
-				y[i][0] = y[i][0] - s.a[0][j+1]*y[i-j-1][0]
-				y[i][1] = y[i][1] - s.a[1][j+1]*y[i-j-1][1]
-			}
-		}
-
-		y[i][0] = y[i][0] / s.a[0][0]
-		y[i][1] = y[i][1] / s.a[1][0]
-	}
-
-	s.xPast = x[:]
-	s.yPast = y[:]
-	copy(x, y)
+	return section{l[0], r[0]}
 }
@@ -0,0 +1,150 @@
+package effects_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+)
+
+// sineStreamer streams a sine wave of freq in both channels.
+func sineStreamer(sr beep.SampleRate, freq float64) beep.Streamer[float64, beep.Stereo[float64]] {
+	var phase float64
+	return beep.StreamerFunc[float64, beep.Stereo[float64]](func(samples []beep.Stereo[float64]) (n int, ok bool) {
+		for i := range samples {
+			v := math.Sin(phase)
+			samples[i] = beep.Stereo[float64]{v, v}
+			phase += 2 * math.Pi * freq / float64(sr)
+		}
+		return len(samples), true
+	})
+}
+
+// amplitude streams a sine through s for a second to let it settle and returns the amplitude of
+// each channel over the following second, measured from the RMS.
+func amplitude(s beep.Streamer[float64, beep.Stereo[float64]], sr beep.SampleRate) (l, r float64) {
+	buf := make([]beep.Stereo[float64], sr)
+	s.Stream(buf)
+	s.Stream(buf)
+	for _, p := range buf {
+		l += p[0] * p[0]
+		r += p[1] * p[1]
+	}
+	return math.Sqrt(2 * l / float64(len(buf))), math.Sqrt(2 * r / float64(len(buf)))
+}
+
+func TestEqualizerResponse(t *testing.T) {
+	const sr = beep.SampleRate(44100)
+	sections := effects.StereoEqualizerSections[float64, beep.Stereo[float64]]{
+		{
+			Left:  effects.MonoEqualizerSection[float64, beep.Stereo[float64]]{F0: 1000, Bf: 200, GB: 3, G0: 0, G: 6},
+			Right: effects.MonoEqualizerSection[float64, beep.Stereo[float64]]{F0: 1000, Bf: 200, GB: -3, G0: 0, G: -6},
+		},
+	}
+	for _, tc := range []struct {
+		freq        float64
+		left, right float64 // dB
+	}{
+		{1000, 6, -6},
+		{30, 0, 0},
+		{15000, 0, 0},
+	} {
+		eq := effects.NewEqualizer[float64, beep.Stereo[float64]](sineStreamer(sr, tc.freq), sr, sections)
+		l, r := amplitude(eq, sr)
+		if math.Abs(dB(l)-tc.left) > 0.1 || math.Abs(dB(r)-tc.right) > 0.1 {
+			t.Errorf("%v Hz: got %.2f dB, %.2f dB, want %.2f dB, %.2f dB", tc.freq, dB(l), dB(r), tc.left, tc.right)
+		}
+	}
+}
+
+func TestEqualizerSetSections(t *testing.T) {
+	const sr = beep.SampleRate(44100)
+	mono := func(g float64) effects.MonoEqualizerSections[float64, beep.Stereo[float64]] {
+		return effects.MonoEqualizerSections[float64, beep.Stereo[float64]]{
+			{F0: 500, Bf: 100, GB: g / 2, G0: 0, G: g},
+			{F0: 5000, Bf: 1000, GB: 1.5, G0: 0, G: 3},
+		}
+	}
+	eq := effects.NewEqualizer[float64, beep.Stereo[float64]](sineStreamer(sr, 500), sr, mono(-12))
+	l, _ := amplitude(eq, sr)
+	if math.Abs(dB(l)+12) > 0.2 {
+		t.Errorf("got %.2f dB, want -12 dB", dB(l))
+	}
+
+	eq.SetSections(mono(9))
+	buf := make([]beep.Stereo[float64], 64)
+	prev := 0.0
+	for i := 0; i < 100; i++ {
+		eq.Stream(buf)
+		for _, p := range buf {
+			// a 500 Hz sine at +9 dB can't move more than this between samples
+			if math.Abs(p[0]-prev) > 2*math.Pi*500/float64(sr)*math.Pow(10, 9.0/20)*1.5 {
+				t.Fatalf("discontinuity while changing sections: %f to %f", prev, p[0])
+			}
+			prev = p[0]
+		}
+	}
+	l, _ = amplitude(eq, sr)
+	if math.Abs(dB(l)-9) > 0.2 {
+		t.Errorf("got %.2f dB, want 9 dB", dB(l))
+	}
+}
+
+func TestEqualizerBlockSizes(t *testing.T) {
+	const sr = beep.SampleRate(44100)
+	data := make([]beep.Stereo[float64], 5000)
+	for i := range data {
+		data[i] = beep.Stereo[float64]{rand.Float64()*2 - 1, rand.Float64()*2 - 1}
+	}
+	sections := effects.MonoEqualizerSections[float64, beep.Stereo[float64]]{
+		{F0: 200, Bf: 50, GB: 3, G0: 0, G: 6},
+		{F0: 3000, Bf: 500, GB: -4, G0: 0, G: -8},
+	}
+
+	run := func(sizes func() int) []beep.Stereo[float64] {
+		pos := 0
+		s := beep.StreamerFunc[float64, beep.Stereo[float64]](func(samples []beep.Stereo[float64]) (n int, ok bool) {
+			if pos >= len(data) {
+				return 0, false
+			}
+			n = copy(samples, data[pos:])
+			pos += n
+			return n, true
+		})
+		eq := effects.NewEqualizer[float64, beep.Stereo[float64]](s, sr, sections)
+		var out []beep.Stereo[float64]
+		for {
+			buf := make([]beep.Stereo[float64], sizes())
+			n, ok := eq.Stream(buf)
+			if !ok {
+				return out
+			}
+			out = append(out, buf[:n]...)
+		}
+	}
+
+	want := run(func() int { return len(data) })
+	got := run(func() int { return rand.Intn(3) + 1 })
+	for i := range want {
+		if math.Abs(want[i][0]-got[i][0]) > 1e-12 || math.Abs(want[i][1]-got[i][1]) > 1e-12 {
+			t.Fatalf("sample %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestEqualizerAllocs(t *testing.T) {
+	const sr = beep.SampleRate(44100)
+	eq := effects.NewEqualizer[float32, beep.Stereo[float32]](beep.Silence[float32, beep.Stereo[float32]](-1), sr, effects.MonoEqualizerSections[float32, beep.Stereo[float32]]{
+		{F0: 200, Bf: 50, GB: 3, G0: 0, G: 6},
+	})
+	buf := make([]beep.Stereo[float32], 512)
+	eq.Stream(buf)
+	allocs := testing.AllocsPerRun(100, func() {
+		eq.Stream(buf)
+	})
+	if allocs != 0 {
+		t.Errorf("Stream allocates %v times per call", allocs)
+	}
+}
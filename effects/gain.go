@@ -1,6 +1,9 @@
 package effects
 
-import "github.com/faiface/beep"
+import (
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+)
 
 // Gain amplifies the wrapped Streamer. The output of the wrapped Streamer gets multiplied by
 // 1+Gain.
@@ -15,10 +18,11 @@ type Gain[S beep.Size, P beep.Point[S]] struct {
 // Stream streams the wrapped Streamer amplified by Gain.
 func (g *Gain[S, P]) Stream(samples []P) (n int, ok bool) {
 	n, ok = g.Streamer.Stream(samples)
-	for i := range samples[:n] {
-		samples[i][0] *= S(1 + g.Gain)
-		samples[i][1] *= S(1 + g.Gain)
-	}
+	points.Each(samples[:n], func(ch []S) {
+		for c := range ch {
+			ch[c] *= S(1 + g.Gain)
+		}
+	})
 	return n, ok
 }
 
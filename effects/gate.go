@@ -0,0 +1,178 @@
+package effects
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+)
+
+// Gate silences the wrapped Streamer while its level is below Threshold, such as the background
+// noise between phrases. With Ratio above 1, it becomes a downward expander, which attenuates
+// quiet parts gradually instead of shutting them off.
+//
+// The gate opens when the level rises to Threshold and closes when it falls below Threshold minus
+// Hysteresis for longer than Hold. This keeps a signal hovering around the threshold from making
+// the gate chatter.
+//
+// The level is detected over all channels together, either from the wrapped Streamer or from the
+// Sidechain, and is passed through the KeyFilter first. A high pass key filter, for example,
+// keeps low rumble from opening the gate.
+//
+// If you're playing the Gate through the speaker, lock the speaker when modifying its fields,
+// except for reading GainReduction, which is safe at any time.
+type Gate[S beep.Size, P beep.Point[S]] struct {
+	Streamer   beep.Streamer[S, P]
+	SampleRate beep.SampleRate
+
+	// Sidechain, if not nil, is used to detect the level instead of Streamer. Once drained, it's
+	// treated as silence.
+	Sidechain beep.Streamer[S, P]
+	// KeyFilter are the sections of a filter applied to the detected signal, not to the output.
+	KeyFilter []Biquad
+
+	// Threshold is the level in decibels [dB] at which the gate opens. 0 dB is full scale.
+	Threshold float64
+	// Hysteresis is how many decibels [dB] below Threshold the level has to fall for the gate to
+	// close.
+	Hysteresis float64
+	// Range is the gain in decibels [dB] of the closed gate, for example -80. It should be
+	// negative. Less negative values only duck the noise instead of removing it.
+	Range float64
+	// Ratio turns the gate into a downward expander if above 1. Below the threshold, the output
+	// falls Ratio decibels for every decibel the input falls, down to Range.
+	Ratio float64
+	// Attack is the time it takes to open the gate by 63% of the way.
+	Attack time.Duration
+	// Hold is the time the gate stays open after the level falls below the closing threshold.
+	Hold time.Duration
+	// Release is the time it takes to close the gate by 63% of the way.
+	Release time.Duration
+	// Detection selects Peak or RMS level detection.
+	Detection Detection
+
+	env       float64 // smoothed gain in dB
+	ms        float64 // mean square for RMS detection
+	open      bool
+	held      int // samples the gate has been held open
+	key       [][]biquadState
+	keyCoefs  []biquadCoefs
+	side      []P
+	sideDone  bool
+	reduction atomic.Uint64 // bits of the gain reduction in dB, for metering
+}
+
+// Stream streams the wrapped Streamer gated.
+func (g *Gate[S, P]) Stream(samples []P) (n int, ok bool) {
+	n, ok = g.Streamer.Stream(samples)
+
+	var det []P
+	if g.Sidechain != nil {
+		if cap(g.side) < n {
+			g.side = make([]P, n)
+		}
+		det = g.side[:n]
+		sn := 0
+		if !g.sideDone {
+			var sok bool
+			sn, sok = g.Sidechain.Stream(det)
+			if !sok {
+				g.sideDone = true
+			}
+		}
+		for i := sn; i < len(det); i++ {
+			var zero P
+			det[i] = zero
+		}
+	}
+
+	g.updateKeyFilter()
+
+	attack := timeCoef(g.Attack, g.SampleRate)
+	release := timeCoef(g.Release, g.SampleRate)
+	rms := timeCoef(rmsWindow, g.SampleRate)
+	hold := g.SampleRate.N(g.Hold)
+
+	i := 0
+	points.Each(samples[:n], func(ch []S) {
+		var peak float64
+		for k := range ch {
+			x := float64(ch[k])
+			if det != nil {
+				x = float64(det[i].Get(k))
+			}
+			for j, c := range g.keyCoefs {
+				x = g.key[j][k].process(c, x)
+			}
+			peak = math.Max(peak, math.Abs(x))
+		}
+		i++
+		level := peak
+		if g.Detection == RMS {
+			g.ms = rms*g.ms + (1-rms)*peak*peak
+			level = math.Sqrt(g.ms)
+		}
+		level = 20 * math.Log10(math.Max(level, 1e-10))
+
+		switch {
+		case level >= g.Threshold:
+			g.open = true
+			g.held = 0
+		case g.open && level < g.Threshold-g.Hysteresis:
+			g.held++
+			if g.held > hold {
+				g.open = false
+			}
+		}
+
+		target := 0.0
+		if !g.open {
+			target = g.Range
+			if g.Ratio > 1 {
+				target = math.Max(g.Range, (level-g.Threshold)*(g.Ratio-1))
+			}
+		}
+		if target > g.env {
+			g.env = attack*g.env + (1-attack)*target
+		} else {
+			g.env = release*g.env + (1-release)*target
+		}
+
+		gain := S(math.Pow(10, g.env/20))
+		for k := range ch {
+			ch[k] *= gain
+		}
+	})
+
+	g.reduction.Store(math.Float64bits(-g.env))
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (g *Gate[S, P]) Err() error {
+	return g.Streamer.Err()
+}
+
+// GainReduction returns the current gain reduction in decibels [dB]. It's safe to call from any
+// goroutine.
+func (g *Gate[S, P]) GainReduction() float64 {
+	return math.Float64frombits(g.reduction.Load())
+}
+
+// updateKeyFilter recalculates the key filter coefficients, keeping the state if the number of
+// sections didn't change.
+func (g *Gate[S, P]) updateKeyFilter() {
+	if len(g.keyCoefs) != len(g.KeyFilter) {
+		var p P
+		g.keyCoefs = make([]biquadCoefs, len(g.KeyFilter))
+		g.key = make([][]biquadState, len(g.KeyFilter))
+		for j := range g.key {
+			g.key[j] = make([]biquadState, p.Count())
+		}
+	}
+	for j, b := range g.KeyFilter {
+		g.keyCoefs[j] = b.coefs(g.SampleRate)
+	}
+}
@@ -0,0 +1,92 @@
+package effects_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+)
+
+func TestGate(t *testing.T) {
+	const sr = beep.SampleRate(10000)
+	newGate := func(s beep.Streamer[float64, beep.Stereo[float64]]) *effects.Gate[float64, beep.Stereo[float64]] {
+		return &effects.Gate[float64, beep.Stereo[float64]]{
+			Streamer:   s,
+			SampleRate: sr,
+			Threshold:  -30,
+			Hysteresis: 6,
+			Range:      -60,
+			Attack:     time.Millisecond,
+			Hold:       100 * time.Millisecond,
+			Release:    5 * time.Millisecond,
+		}
+	}
+
+	// loud, then between the closing and the opening threshold, then quiet
+	g := newGate(beep.Seq(
+		constant(int(sr), 0.1),
+		constant(int(sr), math.Pow(10, -33.0/20)),
+		constant(int(sr), 0.001),
+	))
+	got := collectN(g, -1)
+	for _, tc := range []struct {
+		at   int
+		want float64 // gain in dB
+	}{
+		{int(sr) - 1, 0},
+		{2*int(sr) - 1, 0},                         // hysteresis keeps the gate open
+		{2*int(sr) + sr.N(50*time.Millisecond), 0}, // hold
+		{3*int(sr) - 1, -60},
+	} {
+		in := []float64{0.1, math.Pow(10, -33.0/20), 0.001}[tc.at/int(sr)]
+		if gain := dB(got[tc.at][0] / in); math.Abs(gain-tc.want) > 0.1 {
+			t.Errorf("sample %d: got gain %.2f dB, want %.2f dB", tc.at, gain, tc.want)
+		}
+	}
+	if gr := g.GainReduction(); math.Abs(gr-60) > 0.1 {
+		t.Errorf("got gain reduction %.2f dB, want 60 dB", gr)
+	}
+
+	// the same signal between the thresholds never opens the gate
+	g = newGate(constant(int(sr), math.Pow(10, -33.0/20)))
+	got = collectN(g, -1)
+	if gain := dB(got[len(got)-1][0]) + 33; math.Abs(gain+60) > 0.1 {
+		t.Errorf("closed gate: got gain %.2f dB, want -60 dB", gain)
+	}
+}
+
+func TestGateExpanderAndKeyFilter(t *testing.T) {
+	const sr = beep.SampleRate(44100)
+	g := &effects.Gate[float64, beep.Stereo[float64]]{
+		Streamer:   sineStreamer(sr, 1000),
+		SampleRate: sr,
+		Threshold:  0,
+		Range:      -40,
+		Ratio:      2,
+		Attack:     time.Millisecond,
+		Release:    time.Millisecond,
+		Detection:  effects.RMS,
+	}
+	// the sine is at -3 dB RMS, 3 dB below the threshold, so the expander takes another 3 dB
+	l, _ := amplitude(g, sr)
+	if math.Abs(dB(l)+3) > 0.2 {
+		t.Errorf("expander: got %.2f dB, want -3 dB", dB(l))
+	}
+
+	// a low hum must not open the gate with a high pass key filter
+	g = &effects.Gate[float64, beep.Stereo[float64]]{
+		Streamer:   sineStreamer(sr, 50),
+		SampleRate: sr,
+		KeyFilter:  effects.Butterworth(effects.HighPass, 500, 4),
+		Threshold:  -20,
+		Range:      -60,
+		Attack:     time.Millisecond,
+		Release:    time.Millisecond,
+	}
+	l, _ = amplitude(g, sr)
+	if math.Abs(dB(l)+60) > 0.2 {
+		t.Errorf("key filter: got %.2f dB, want -60 dB", dB(l))
+	}
+}
@@ -0,0 +1,319 @@
+package effects
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+
+	"github.com/faiface/beep"
+)
+
+var (
+	iso10Bands = [...]float64{31.5, 63, 125, 250, 500, 1000, 2000, 4000, 8000, 16000}
+	iso31Bands = [...]float64{
+		20, 25, 31.5, 40, 50, 63, 80, 100, 125, 160, 200, 250, 315, 400, 500, 630,
+		800, 1000, 1250, 1600, 2000, 2500, 3150, 4000, 5000, 6300, 8000, 10000, 12500, 16000, 20000,
+	}
+)
+
+// ISO10Bands returns the center frequencies of the octave bands of a 10-band graphic equalizer.
+// Each call returns a new slice, which the caller may modify.
+func ISO10Bands() []float64 {
+	return append([]float64(nil), iso10Bands[:]...)
+}
+
+// ISO31Bands returns the center frequencies of the third-octave bands of a 31-band graphic
+// equalizer. Each call returns a new slice, which the caller may modify.
+func ISO31Bands() []float64 {
+	return append([]float64(nil), iso31Bands[:]...)
+}
+
+// graphicEqualizerProbe is the gain in dB used to measure how the bands of a GraphicEqualizer
+// interact.
+const graphicEqualizerProbe = 12
+
+// GraphicEqualizer is an equalizer with a fixed set of bands, usually ISO10Bands or ISO31Bands,
+// each with a gain slider.
+//
+// Neighbouring bands overlap, so simply setting each section to the gain of its slider would
+// result in a curve with bumps where adjacent sliders are raised together. GraphicEqualizer
+// compensates for that and picks the section gains so that the response at each center frequency
+// matches the slider.
+//
+// All methods of GraphicEqualizer are safe to call from another goroutine while it's being
+// streamed.
+type GraphicEqualizer[S beep.Size, P beep.Point[S]] struct {
+	eq     *Equalizer[S, P]
+	fs     float64
+	bands  []float64
+	bw     []float64   // bandwidth of each section in Hertz
+	usable []bool      // bands below the Nyquist frequency
+	inv    [][]float64 // inverse of the band interaction matrix
+
+	mu    sync.Mutex
+	gains []float64
+}
+
+// NewGraphicEqualizer returns a GraphicEqualizer of s with the given band center frequencies in
+// ascending order, all set to 0 dB. The SampleRate (sr) must match that of the Streamer.
+//
+// Bands too close to the Nyquist frequency (half of sr) can't be realized and are ignored.
+func NewGraphicEqualizer[S beep.Size, P beep.Point[S]](s beep.Streamer[S, P], sr beep.SampleRate, bands []float64) *GraphicEqualizer[S, P] {
+	g := &GraphicEqualizer[S, P]{
+		fs:     float64(sr),
+		bands:  append([]float64(nil), bands...),
+		bw:     make([]float64, len(bands)),
+		usable: make([]bool, len(bands)),
+		gains:  make([]float64, len(bands)),
+	}
+	for i, f := range bands {
+		// each band reaches halfway to its neighbours on the logarithmic scale
+		lo, hi := f, f
+		if i > 0 {
+			lo = bands[i-1]
+		}
+		if i < len(bands)-1 {
+			hi = bands[i+1]
+		}
+		var ratio float64
+		switch {
+		case len(bands) == 1:
+			ratio = 2
+		case i == 0 || i == len(bands)-1:
+			ratio = hi / lo
+		default:
+			ratio = math.Sqrt(hi / lo)
+		}
+		g.bw[i] = f * (math.Sqrt(ratio) - 1/math.Sqrt(ratio))
+		g.usable[i] = f+g.bw[i]/2 < 0.95*g.fs/2
+	}
+
+	g.inv = invert(g.interaction(graphicEqualizerProbe))
+	g.eq = NewEqualizer[S, P](s, sr, g.sections(g.gains))
+	return g
+}
+
+// Stream streams the wrapped Streamer modified by the equalizer.
+func (g *GraphicEqualizer[S, P]) Stream(samples []P) (n int, ok bool) {
+	return g.eq.Stream(samples)
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (g *GraphicEqualizer[S, P]) Err() error {
+	return g.eq.Err()
+}
+
+// Bands returns the center frequencies of the bands.
+func (g *GraphicEqualizer[S, P]) Bands() []float64 {
+	return append([]float64(nil), g.bands...)
+}
+
+// Gain returns the gain of the band with index band in decibels [dB].
+func (g *GraphicEqualizer[S, P]) Gain(band int) float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.gains[band]
+}
+
+// Gains returns the gains of all bands in decibels [dB].
+func (g *GraphicEqualizer[S, P]) Gains() []float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]float64(nil), g.gains...)
+}
+
+// SetGain sets the gain of the band with index band in decibels [dB].
+func (g *GraphicEqualizer[S, P]) SetGain(band int, dB float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.gains[band] = dB
+	g.eq.SetSections(g.sections(g.gains))
+}
+
+// SetGains sets the gains of all bands at once in decibels [dB]. If the number of gains doesn't
+// match the number of bands, SetGains panics.
+func (g *GraphicEqualizer[S, P]) SetGains(dB []float64) {
+	if len(dB) != len(g.bands) {
+		panic(fmt.Errorf("effects: graphic equalizer: got %d gains for %d bands", len(dB), len(g.bands)))
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	copy(g.gains, dB)
+	g.eq.SetSections(g.sections(g.gains))
+}
+
+// Preset returns the current gains as a preset with the given name.
+func (g *GraphicEqualizer[S, P]) Preset(name string) GraphicEqualizerPreset {
+	return GraphicEqualizerPreset{
+		Name:  name,
+		Bands: g.Bands(),
+		Gains: g.Gains(),
+	}
+}
+
+// LoadPreset sets the gains from the preset. The bands of the preset must match the bands of the
+// GraphicEqualizer, otherwise an error is returned and the gains are left unchanged.
+func (g *GraphicEqualizer[S, P]) LoadPreset(p GraphicEqualizerPreset) error {
+	if len(p.Bands) != len(g.bands) || len(p.Gains) != len(g.bands) {
+		return fmt.Errorf("effects: graphic equalizer: preset %q has %d bands, want %d", p.Name, len(p.Bands), len(g.bands))
+	}
+	for i, f := range p.Bands {
+		if math.Abs(f-g.bands[i]) > 1e-6*g.bands[i] {
+			return fmt.Errorf("effects: graphic equalizer: preset %q band %d is %v Hz, want %v Hz", p.Name, i, f, g.bands[i])
+		}
+	}
+	g.SetGains(p.Gains)
+	return nil
+}
+
+// sections returns the equalizer sections realizing the slider gains. The caller must hold mu,
+// unless the GraphicEqualizer isn't shared yet.
+func (g *GraphicEqualizer[S, P]) sections(gains []float64) MonoEqualizerSections[S, P] {
+	// first guess from the interaction matrix, which assumes the bands add up linearly in dB
+	section := mulVec(g.inv, gains)
+
+	// the responses don't scale linearly with gain, so correct the remaining error
+	for iter := 0; iter < 2; iter++ {
+		got := g.response(section)
+		diff := make([]float64, len(gains))
+		for i := range diff {
+			if g.usable[i] {
+				diff[i] = gains[i] - got[i]
+			}
+		}
+		for i, d := range mulVec(g.inv, diff) {
+			section[i] += d
+		}
+	}
+
+	out := make(MonoEqualizerSections[S, P], 0, len(g.bands))
+	for i := range g.bands {
+		if g.usable[i] {
+			out = append(out, g.section(i, section[i]))
+		}
+	}
+	return out
+}
+
+// section returns the equalizer section of the band i with the gain dB.
+func (g *GraphicEqualizer[S, P]) section(i int, dB float64) MonoEqualizerSection[S, P] {
+	return MonoEqualizerSection[S, P]{F0: g.bands[i], Bf: g.bw[i], GB: dB / 2, G0: 0, G: dB}
+}
+
+// response returns the response in dB at each center frequency of the sections with the given
+// gains.
+func (g *GraphicEqualizer[S, P]) response(gains []float64) []float64 {
+	out := make([]float64, len(g.bands))
+	for i := range g.bands {
+		if !g.usable[i] {
+			continue
+		}
+		c := g.section(i, gains[i]).section(g.fs)[0]
+		for j, f := range g.bands {
+			if g.usable[j] {
+				out[j] += 20 * math.Log10(c.response(2*math.Pi*f/g.fs))
+			}
+		}
+	}
+	return out
+}
+
+// interaction returns the matrix whose column i is the response in dB at the center frequencies
+// of the band i set to probe dB, divided by probe. Unusable bands get identity rows and columns,
+// so the matrix stays invertible.
+func (g *GraphicEqualizer[S, P]) interaction(probe float64) [][]float64 {
+	m := make([][]float64, len(g.bands))
+	for j := range m {
+		m[j] = make([]float64, len(g.bands))
+	}
+	for i := range g.bands {
+		if !g.usable[i] {
+			m[i][i] = 1
+			continue
+		}
+		c := g.section(i, probe).section(g.fs)[0]
+		for j, f := range g.bands {
+			if g.usable[j] {
+				m[j][i] = 20 * math.Log10(c.response(2*math.Pi*f/g.fs)) / probe
+			}
+		}
+	}
+	return m
+}
+
+// invert returns the inverse of the square matrix m using Gauss-Jordan elimination with partial
+// pivoting.
+func invert(m [][]float64) [][]float64 {
+	n := len(m)
+	a := make([][]float64, n)
+	for i := range a {
+		a[i] = make([]float64, 2*n)
+		copy(a[i], m[i])
+		a[i][n+i] = 1
+	}
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		p := a[col][col]
+		for k := range a[col] {
+			a[col][k] /= p
+		}
+		for row := 0; row < n; row++ {
+			if row == col || a[row][col] == 0 {
+				continue
+			}
+			f := a[row][col]
+			for k := range a[row] {
+				a[row][k] -= f * a[col][k]
+			}
+		}
+	}
+	inv := make([][]float64, n)
+	for i := range inv {
+		inv[i] = a[i][n:]
+	}
+	return inv
+}
+
+func mulVec(m [][]float64, v []float64) []float64 {
+	out := make([]float64, len(m))
+	for i, row := range m {
+		for j, x := range row {
+			out[i] += x * v[j]
+		}
+	}
+	return out
+}
+
+// GraphicEqualizerPreset is a named set of gains for a GraphicEqualizer.
+type GraphicEqualizerPreset struct {
+	Name  string    `json:"name"`
+	Bands []float64 `json:"bands"` // center frequencies in Hertz [Hz]
+	Gains []float64 `json:"gains"` // gains in decibels [dB]
+}
+
+// WriteGraphicEqualizerPresets writes the presets to w as JSON.
+func WriteGraphicEqualizerPresets(w io.Writer, presets ...GraphicEqualizerPreset) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	if err := enc.Encode(presets); err != nil {
+		return fmt.Errorf("effects: graphic equalizer: writing presets: %w", err)
+	}
+	return nil
+}
+
+// ReadGraphicEqualizerPresets reads presets written by WriteGraphicEqualizerPresets from r.
+func ReadGraphicEqualizerPresets(r io.Reader) ([]GraphicEqualizerPreset, error) {
+	var presets []GraphicEqualizerPreset
+	if err := json.NewDecoder(r).Decode(&presets); err != nil {
+		return nil, fmt.Errorf("effects: graphic equalizer: reading presets: %w", err)
+	}
+	return presets, nil
+}
@@ -0,0 +1,80 @@
+package effects_test
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+)
+
+func TestGraphicEqualizerMatchesSliders(t *testing.T) {
+	const sr = beep.SampleRate(48000)
+	for _, tc := range []struct {
+		name  string
+		bands []float64
+		gains []float64
+	}{
+		{"10-flat-boost", effects.ISO10Bands(), []float64{6, 6, 6, 6, 6, 6, 6, 6, 6, 6}},
+		{"10-smile", effects.ISO10Bands(), []float64{8, 5, 2, 0, -3, -3, 0, 2, 5, 8}},
+		{"31-alternating", effects.ISO31Bands(), func() []float64 {
+			g := make([]float64, len(effects.ISO31Bands()))
+			for i := range g {
+				g[i] = 4 * math.Sin(float64(i)/2)
+			}
+			return g
+		}()},
+	} {
+		for i, f := range tc.bands {
+			geq := effects.NewGraphicEqualizer[float64, beep.Stereo[float64]](sineStreamer(sr, f), sr, tc.bands)
+			geq.SetGains(tc.gains)
+			l, r := amplitude(geq, sr)
+			if math.Abs(dB(l)-tc.gains[i]) > 0.5 || math.Abs(dB(r)-tc.gains[i]) > 0.5 {
+				t.Errorf("%s: band %v Hz: got %.2f dB, want %.2f dB", tc.name, f, dB(l), tc.gains[i])
+			}
+		}
+	}
+}
+
+func TestGraphicEqualizerPresets(t *testing.T) {
+	const sr = beep.SampleRate(44100)
+	geq := effects.NewGraphicEqualizer[float64, beep.Stereo[float64]](beep.Silence[float64, beep.Stereo[float64]](-1), sr, effects.ISO10Bands())
+	geq.SetGain(0, 4)
+	geq.SetGain(9, -2.5)
+	presets := []effects.GraphicEqualizerPreset{geq.Preset("bass"), {Name: "flat", Bands: effects.ISO10Bands(), Gains: make([]float64, 10)}}
+
+	var buf bytes.Buffer
+	if err := effects.WriteGraphicEqualizerPresets(&buf, presets...); err != nil {
+		t.Fatal(err)
+	}
+	got, err := effects.ReadGraphicEqualizerPresets(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, presets) {
+		t.Fatalf("got %v, want %v", got, presets)
+	}
+
+	if err := geq.LoadPreset(got[1]); err != nil {
+		t.Fatal(err)
+	}
+	if g := geq.Gains(); !reflect.DeepEqual(g, make([]float64, 10)) {
+		t.Errorf("got gains %v after loading flat preset", g)
+	}
+	if err := geq.LoadPreset(effects.GraphicEqualizerPreset{Bands: effects.ISO31Bands(), Gains: make([]float64, 31)}); err == nil {
+		t.Errorf("loading a 31-band preset into a 10-band equalizer succeeded")
+	}
+}
+
+func TestISOBandsAreCopies(t *testing.T) {
+	bands := effects.ISO10Bands()
+	bands[0] = 0
+	if effects.ISO10Bands()[0] != 31.5 {
+		t.Errorf("modifying the returned bands changed ISO10Bands")
+	}
+	if n := len(effects.ISO31Bands()); n != 31 {
+		t.Errorf("got %d bands, want 31", n)
+	}
+}
@@ -0,0 +1,104 @@
+package effects
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/faiface/beep"
+)
+
+// LFOShape is the waveform of an LFO.
+type LFOShape int
+
+const (
+	// Sine is a smooth sine wave.
+	Sine LFOShape = iota
+	// Triangle rises and falls linearly.
+	Triangle
+	// Square jumps between the extremes every half cycle.
+	Square
+	// Random glides smoothly to a new random value every cycle.
+	Random
+)
+
+// String returns the name of the shape.
+func (s LFOShape) String() string {
+	switch s {
+	case Sine:
+		return "Sine"
+	case Triangle:
+		return "Triangle"
+	case Square:
+		return "Square"
+	case Random:
+		return "Random"
+	}
+	return fmt.Sprintf("LFOShape(%d)", int(s))
+}
+
+// lfoWrap is the number of cycles after which the phase of an LFO wraps around, to keep it
+// precise.
+const lfoWrap = 1 << 20
+
+// LFO is a low frequency oscillator, which drives the modulation effects, such as Chorus or
+// Tremolo. Its output is between -Depth and Depth; what that means is up to the effect.
+//
+// Each channel has its own phase, offset from the previous channel by StereoPhase. An offset of
+// 0.5 makes the left and the right channel move in opposite directions, which widens the stereo
+// image.
+type LFO struct {
+	Shape LFOShape
+	// Rate is the frequency of the LFO in Hertz [Hz].
+	Rate float64
+	// Depth between 0 and 1 scales the output of the LFO.
+	Depth float64
+	// StereoPhase is the phase offset of each channel from the previous one in cycles, for example
+	// 0.25 for 90°.
+	StereoPhase float64
+
+	phase float64 // in cycles
+}
+
+// advance moves the LFO forward by one sample.
+func (l *LFO) advance(sr beep.SampleRate) {
+	l.phase += l.Rate / float64(sr)
+	if l.phase >= lfoWrap {
+		l.phase -= lfoWrap
+	}
+}
+
+// at returns the current output of the LFO for the channel c.
+func (l *LFO) at(c int) float64 {
+	p := l.phase + float64(c)*l.StereoPhase
+	cycle := math.Floor(p)
+	x := p - cycle
+
+	var y float64
+	switch l.Shape {
+	case Triangle:
+		x += 0.75
+		y = 4*math.Abs(x-math.Floor(x)-0.5) - 1
+	case Square:
+		y = 1
+		if x >= 0.5 {
+			y = -1
+		}
+	case Random:
+		k := uint64(cycle) % lfoWrap
+		a, b := lfoRandom(k), lfoRandom((k+1)%lfoWrap)
+		y = a + (b-a)*(1-math.Cos(math.Pi*x))/2
+	default:
+		y = math.Sin(2 * math.Pi * x)
+	}
+	return l.Depth * y
+}
+
+// lfoRandom returns a pseudo-random value between -1 and 1 for the cycle k, so that the Random
+// shape needs no state besides the phase. It's the SplitMix64 hash.
+func lfoRandom(k uint64) float64 {
+	z := k + 0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	z ^= z >> 31
+	return float64(z>>11)/(1<<52) - 1
+}
@@ -0,0 +1,66 @@
+package effects_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+)
+
+// lfoOutput returns the output of lfo for the left and the right channel, recovered from a
+// Tremolo with Base of 2.
+func lfoOutput(lfo effects.LFO, sr beep.SampleRate, n int) (l, r []float64) {
+	t := &effects.Tremolo[float64, beep.Stereo[float64]]{
+		Streamer:   constant(n, 1),
+		SampleRate: sr,
+		LFO:        lfo,
+		Base:       2,
+	}
+	for _, p := range collectN(t, -1) {
+		l = append(l, math.Log2(p[0]))
+		r = append(r, math.Log2(p[1]))
+	}
+	return l, r
+}
+
+func TestLFOShapes(t *testing.T) {
+	const sr = 8000
+	tests := []struct {
+		shape effects.LFOShape
+		want  func(x float64) float64 // x is the phase in cycles
+	}{
+		{effects.Sine, func(x float64) float64 { return math.Sin(2 * math.Pi * x) }},
+		{effects.Triangle, func(x float64) float64 { return []float64{0, 0.5, 1, 0.5, 0, -0.5, -1, -0.5}[int(x*8)%8] }},
+		{effects.Square, func(x float64) float64 { return []float64{1, -1}[int(x*2)%2] }},
+	}
+	for _, test := range tests {
+		l, r := lfoOutput(effects.LFO{Shape: test.shape, Rate: 1000, Depth: 0.5, StereoPhase: 0.5}, sr, 32)
+		for i := range l {
+			x := float64(i) / 8
+			if want := 0.5 * test.want(x); math.Abs(l[i]-want) > 1e-9 {
+				t.Errorf("%v: left sample %d: got %v, want %v", test.shape, i, l[i], want)
+			}
+			if want := 0.5 * test.want(x+0.5); math.Abs(r[i]-want) > 1e-9 {
+				t.Errorf("%v: right sample %d: got %v, want %v", test.shape, i, r[i], want)
+			}
+		}
+	}
+}
+
+func TestLFORandom(t *testing.T) {
+	l, _ := lfoOutput(effects.LFO{Shape: effects.Random, Rate: 10, Depth: 1}, 1000, 10000)
+	var lo, hi float64
+	for i, x := range l {
+		if math.Abs(x) > 1 {
+			t.Fatalf("sample %d: %v out of range", i, x)
+		}
+		if i > 0 && math.Abs(x-l[i-1]) > 0.1 {
+			t.Fatalf("sample %d: jumps from %v to %v", i, l[i-1], x)
+		}
+		lo, hi = math.Min(lo, x), math.Max(hi, x)
+	}
+	if hi-lo < 1 {
+		t.Errorf("the output only covers %v to %v", lo, hi)
+	}
+}
@@ -0,0 +1,225 @@
+package effects
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+)
+
+// EncodeMidSide converts the left and the right channel of the wrapped Streamer to the mid and the
+// side channel. The mid channel, the sum of left and right divided by two, goes to the first
+// channel, the side channel, their difference divided by two, goes to the second channel.
+//
+// DecodeMidSide converts them back.
+//
+// The returned Streamer propagates s's errors through Err.
+func EncodeMidSide[S beep.Size, P beep.Point[S]](s beep.Streamer[S, P]) beep.Streamer[S, P] {
+	return &midSide[S, P]{s, true}
+}
+
+// DecodeMidSide converts the mid and the side channel of the wrapped Streamer, as produced by
+// EncodeMidSide, back to the left and the right channel.
+//
+// The returned Streamer propagates s's errors through Err.
+func DecodeMidSide[S beep.Size, P beep.Point[S]](s beep.Streamer[S, P]) beep.Streamer[S, P] {
+	return &midSide[S, P]{s, false}
+}
+
+type midSide[S beep.Size, P beep.Point[S]] struct {
+	Streamer beep.Streamer[S, P]
+	encode   bool
+}
+
+func (m *midSide[S, P]) Stream(samples []P) (n int, ok bool) {
+	n, ok = m.Streamer.Stream(samples)
+	points.Each(samples[:n], func(ch []S) {
+		if len(ch) < 2 {
+			return
+		}
+		if m.encode {
+			ch[0], ch[1] = (ch[0]+ch[1])/2, (ch[0]-ch[1])/2
+		} else {
+			ch[0], ch[1] = ch[0]+ch[1], ch[0]-ch[1]
+		}
+	})
+	return n, ok
+}
+
+func (m *midSide[S, P]) Err() error {
+	return m.Streamer.Err()
+}
+
+// MidSideChannel selects the mid or the side channel.
+type MidSideChannel int
+
+const (
+	// Mid is the sum of the left and the right channel, what they have in common.
+	Mid MidSideChannel = iota
+	// Side is the difference of the left and the right channel, what makes the stereo image.
+	Side
+)
+
+// ProcessMidSide processes only the mid or the side channel of the wrapped Streamer by the chain
+// of effects built by the chain function, and leaves the other one untouched. For example, this
+// compresses only the center of a mix:
+//
+//	s = effects.ProcessMidSide(s, effects.Mid, func(mid beep.Streamer[float64, beep.Stereo[float64]]) beep.Streamer[float64, beep.Stereo[float64]] {
+//		return &effects.Compressor[float64, beep.Stereo[float64]]{Streamer: mid, ...}
+//	})
+//
+// The chain function gets a Streamer with the selected channel in all channels. The first channel
+// of the Streamer it returns becomes the new selected channel. The chain must not delay the
+// signal, or the channels will be out of sync. It must not read ahead either, that is, stream more
+// samples from the Streamer it gets than it's asked to stream, otherwise the returned Streamer
+// panics.
+//
+// The returned Streamer propagates the chain's errors through Err.
+func ProcessMidSide[S beep.Size, P beep.Point[S]](s beep.Streamer[S, P], channel MidSideChannel, chain func(beep.Streamer[S, P]) beep.Streamer[S, P]) beep.Streamer[S, P] {
+	src := &midSideSource[S, P]{s: s, channel: channel}
+	return &midSideProcess[S, P]{src: src, chain: chain(src)}
+}
+
+// midSideSource streams the selected channel of s in all channels and keeps the other channel for
+// midSideProcess.
+type midSideSource[S beep.Size, P beep.Point[S]] struct {
+	s       beep.Streamer[S, P]
+	channel MidSideChannel
+	other   []S // the other channel of the samples streamed so far, waiting to be mixed back
+}
+
+// reserve makes room for the other channel of n more samples. Stream doesn't allocate, it panics
+// if there's no room.
+func (m *midSideSource[S, P]) reserve(n int) {
+	if cap(m.other)-len(m.other) < n {
+		other := make([]S, len(m.other), len(m.other)+n)
+		copy(other, m.other)
+		m.other = other
+	}
+}
+
+func (m *midSideSource[S, P]) Stream(samples []P) (n int, ok bool) {
+	if len(m.other)+len(samples) > cap(m.other) {
+		panic(fmt.Errorf("effects: mid-side: the chain reads ahead"))
+	}
+	n, ok = m.s.Stream(samples)
+	other := m.other[len(m.other) : len(m.other)+n]
+	m.other = m.other[:len(m.other)+n]
+	i := 0
+	points.Each(samples[:n], func(ch []S) {
+		if len(ch) < 2 {
+			other[i] = 0
+			i++
+			return
+		}
+		mid, side := (ch[0]+ch[1])/2, (ch[0]-ch[1])/2
+		x := mid
+		if m.channel == Side {
+			x, side = side, mid
+		}
+		other[i] = side
+		i++
+		for c := range ch {
+			ch[c] = x
+		}
+	})
+	return n, ok
+}
+
+func (m *midSideSource[S, P]) Err() error {
+	return m.s.Err()
+}
+
+type midSideProcess[S beep.Size, P beep.Point[S]] struct {
+	src   *midSideSource[S, P]
+	chain beep.Streamer[S, P]
+}
+
+func (m *midSideProcess[S, P]) Stream(samples []P) (n int, ok bool) {
+	m.src.reserve(len(samples))
+	n, ok = m.chain.Stream(samples)
+	i := 0
+	points.Each(samples[:n], func(ch []S) {
+		// the chain may stream more samples than it got, such as a tail, those have no other channel
+		var other S
+		if i < len(m.src.other) {
+			other = m.src.other[i]
+		}
+		i++
+		if len(ch) < 2 {
+			return
+		}
+		mid, side := ch[0], other
+		if m.src.channel == Side {
+			mid, side = other, ch[0]
+		}
+		ch[0], ch[1] = mid+side, mid-side
+	})
+	if i >= len(m.src.other) {
+		m.src.other = m.src.other[:0]
+	} else {
+		m.src.other = m.src.other[:copy(m.src.other, m.src.other[i:])]
+	}
+	return n, ok
+}
+
+func (m *midSideProcess[S, P]) Err() error {
+	return m.chain.Err()
+}
+
+// Widener changes the stereo width of the wrapped Streamer by scaling its side channel. Width of
+// 0 makes it mono, 1 changes nothing and 2 makes it extra wide.
+//
+// Wide bass sounds muddy and wastes energy on speakers, so with BassMono set, the side channel
+// below that frequency is removed, which makes the bass mono.
+//
+// If you're playing the Widener through the speaker, lock the speaker when modifying its fields.
+type Widener[S beep.Size, P beep.Point[S]] struct {
+	Streamer   beep.Streamer[S, P]
+	SampleRate beep.SampleRate
+	Width      float64
+	// BassMono is the crossover frequency in Hertz [Hz] below which the signal is made mono, for
+	// example 120. Zero disables it.
+	BassMono float64
+
+	bassAt    float64 // BassMono for which bassCoefs were calculated
+	bassCoefs []biquadCoefs
+	bass      []biquadState
+}
+
+// Stream streams the wrapped Streamer with adjusted width.
+func (w *Widener[S, P]) Stream(samples []P) (n int, ok bool) {
+	n, ok = w.Streamer.Stream(samples)
+	if w.BassMono != w.bassAt {
+		w.bassAt = w.BassMono
+		w.bassCoefs = nil
+		if w.BassMono > 0 {
+			for _, b := range LinkwitzRiley(HighPass, w.BassMono, 4) {
+				w.bassCoefs = append(w.bassCoefs, b.coefs(w.SampleRate))
+			}
+		}
+		if len(w.bass) != len(w.bassCoefs) {
+			w.bass = make([]biquadState, len(w.bassCoefs))
+		}
+	}
+
+	points.Each(samples[:n], func(ch []S) {
+		if len(ch) < 2 {
+			return
+		}
+		mid := (float64(ch[0]) + float64(ch[1])) / 2
+		side := (float64(ch[0]) - float64(ch[1])) / 2
+		for i, c := range w.bassCoefs {
+			side = w.bass[i].process(c, side)
+		}
+		side *= math.Max(0, w.Width)
+		ch[0], ch[1] = S(mid+side), S(mid-side)
+	})
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (w *Widener[S, P]) Err() error {
+	return w.Streamer.Err()
+}
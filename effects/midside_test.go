@@ -0,0 +1,133 @@
+package effects_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+)
+
+func TestMidSideRoundTrip(t *testing.T) {
+	data := []beep.Stereo[float64]{{1, 0}, {0, 1}, {0.5, -0.5}, {0.25, 0.75}}
+	ms := collectN(effects.EncodeMidSide(sliceStreamer(data)), -1)
+	want := []beep.Stereo[float64]{{0.5, 0.5}, {0.5, -0.5}, {0, 0.5}, {0.5, -0.25}}
+	for i := range want {
+		if ms[i] != want[i] {
+			t.Errorf("encoded sample %d: got %v, want %v", i, ms[i], want[i])
+		}
+	}
+	lr := collectN(effects.DecodeMidSide(sliceStreamer(ms)), -1)
+	for i := range data {
+		if lr[i] != data[i] {
+			t.Errorf("decoded sample %d: got %v, want %v", i, lr[i], data[i])
+		}
+	}
+}
+
+func TestProcessMidSide(t *testing.T) {
+	data := make([]beep.Stereo[float64], 1000)
+	for i := range data {
+		data[i] = beep.Stereo[float64]{math.Sin(float64(i) / 10), math.Cos(float64(i) / 7)}
+	}
+	half := func(s beep.Streamer[float64, beep.Stereo[float64]]) beep.Streamer[float64, beep.Stereo[float64]] {
+		return &effects.Gain[float64, beep.Stereo[float64]]{Streamer: s, Gain: -0.5}
+	}
+
+	for _, channel := range []effects.MidSideChannel{effects.Mid, effects.Side} {
+		got := collectN(effects.ProcessMidSide(sliceStreamer(data), channel, half), -1)
+		if len(got) != len(data) {
+			t.Fatalf("got %d samples, want %d", len(got), len(data))
+		}
+		for i, p := range got {
+			mid, side := (data[i][0]+data[i][1])/2, (data[i][0]-data[i][1])/2
+			if channel == effects.Mid {
+				mid /= 2
+			} else {
+				side /= 2
+			}
+			want := beep.Stereo[float64]{mid + side, mid - side}
+			if math.Abs(p[0]-want[0]) > 1e-12 || math.Abs(p[1]-want[1]) > 1e-12 {
+				t.Fatalf("channel %v: sample %d: got %v, want %v", channel, i, p, want)
+			}
+		}
+	}
+}
+
+func TestWidener(t *testing.T) {
+	data := []beep.Stereo[float64]{{1, 0}, {0.5, -0.5}}
+	for _, test := range []struct {
+		width float64
+		want  []beep.Stereo[float64]
+	}{
+		{0, []beep.Stereo[float64]{{0.5, 0.5}, {0, 0}}},
+		{1, data},
+		{2, []beep.Stereo[float64]{{1.5, -0.5}, {1, -1}}},
+	} {
+		w := &effects.Widener[float64, beep.Stereo[float64]]{Streamer: sliceStreamer(data), SampleRate: 44100, Width: test.width}
+		got := collectN(w, -1)
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("width %v: sample %d: got %v, want %v", test.width, i, got[i], test.want[i])
+			}
+		}
+	}
+}
+
+// leftOnly silences the right channel of s.
+func leftOnly(s beep.Streamer[float64, beep.Stereo[float64]]) beep.Streamer[float64, beep.Stereo[float64]] {
+	return beep.StreamerFunc[float64, beep.Stereo[float64]](func(samples []beep.Stereo[float64]) (n int, ok bool) {
+		n, ok = s.Stream(samples)
+		for i := range samples[:n] {
+			samples[i][1] = 0
+		}
+		return n, ok
+	})
+}
+
+func TestWidenerBassMono(t *testing.T) {
+	const sr = beep.SampleRate(44100)
+	side := func(freq float64) float64 {
+		w := &effects.Widener[float64, beep.Stereo[float64]]{
+			Streamer:   leftOnly(sineStreamer(sr, freq)),
+			SampleRate: sr,
+			Width:      1,
+			BassMono:   150,
+		}
+		_, r := amplitude(effects.EncodeMidSide[float64, beep.Stereo[float64]](w), sr)
+		return r
+	}
+	if low, high := side(40), side(2000); low > 0.05 || high < 0.45 {
+		t.Errorf("side level: %v at 40 Hz, %v at 2 kHz", low, high)
+	}
+}
+
+func TestProcessMidSideAllocs(t *testing.T) {
+	ms := effects.ProcessMidSide(constant(-1, 0.5), effects.Mid, func(mid beep.Streamer[float64, beep.Stereo[float64]]) beep.Streamer[float64, beep.Stereo[float64]] {
+		return &effects.Gain[float64, beep.Stereo[float64]]{Streamer: mid, Gain: -0.5}
+	})
+	buf := make([]beep.Stereo[float64], 1024)
+	ms.Stream(buf)
+	allocs := testing.AllocsPerRun(100, func() {
+		ms.Stream(buf)
+	})
+	if allocs != 0 {
+		t.Errorf("Stream allocates %v times per call", allocs)
+	}
+}
+
+func TestProcessMidSideReadAheadPanics(t *testing.T) {
+	ms := effects.ProcessMidSide(constant(-1, 0.5), effects.Mid, func(mid beep.Streamer[float64, beep.Stereo[float64]]) beep.Streamer[float64, beep.Stereo[float64]] {
+		return beep.StreamerFunc[float64, beep.Stereo[float64]](func(samples []beep.Stereo[float64]) (n int, ok bool) {
+			ahead := make([]beep.Stereo[float64], 2*len(samples))
+			mid.Stream(ahead)
+			return copy(samples, ahead), true
+		})
+	})
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	ms.Stream(make([]beep.Stereo[float64], 512))
+}
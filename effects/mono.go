@@ -1,6 +1,9 @@
 package effects
 
-import "github.com/faiface/beep"
+import (
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+)
 
 // Mono converts the wrapped Streamer to a mono buffer
 // by downmixing the left and right channels together.
@@ -16,10 +19,13 @@ type mono[S beep.Size, P beep.Point[S]] struct {
 
 func (m *mono[S, P]) Stream(samples []P) (n int, ok bool) {
 	n, ok = m.Streamer.Stream(samples)
-	for i := range samples[:n] {
-		mix := (samples[i][0] + samples[i][1]) / 2
-		samples[i][0], samples[i][1] = mix, mix
-	}
+	points.Each(samples[:n], func(ch []S) {
+		if len(ch) < 2 {
+			return
+		}
+		mix := (ch[0] + ch[1]) / 2
+		ch[0], ch[1] = mix, mix
+	})
 	return n, ok
 }
 
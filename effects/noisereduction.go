@@ -0,0 +1,331 @@
+package effects
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+	"github.com/faiface/beep/spectrum"
+)
+
+// noiseOverSubtraction is how many times the noise power is subtracted by SpectralSubtraction,
+// which leaves less residual noise at the cost of some signal.
+const noiseOverSubtraction = 2
+
+// NoiseReductionMethod is the rule by which NoiseReduction turns down the frequencies dominated by
+// noise.
+type NoiseReductionMethod int
+
+const (
+	// SpectralSubtraction subtracts the power of the noise from the power of each frequency. It
+	// removes more noise, but is more prone to musical noise, randomly twinkling tones left over
+	// from the noise.
+	SpectralSubtraction NoiseReductionMethod = iota
+	// WienerFilter scales each frequency by its estimated signal-to-noise ratio. It sounds more
+	// natural and leaves less musical noise.
+	WienerFilter
+)
+
+// String returns the name of the method.
+func (m NoiseReductionMethod) String() string {
+	switch m {
+	case SpectralSubtraction:
+		return "SpectralSubtraction"
+	case WienerFilter:
+		return "WienerFilter"
+	}
+	return fmt.Sprintf("NoiseReductionMethod(%d)", int(m))
+}
+
+// NoiseProfile is the average power spectrum of a noise, such as hum or hiss, learned by LearnNoise
+// or NoiseReduction.
+type NoiseProfile struct {
+	SampleRate beep.SampleRate
+	// Power is the power of the noise in evenly spaced frequency bins from 0 Hz to half of the
+	// SampleRate, normalized so that it doesn't depend on the size of the frames it was measured
+	// with. White noise has the power of its variance in every bin.
+	Power []float64
+}
+
+// at returns the power of the noise at freq, interpolated between the bins.
+func (np *NoiseProfile) at(freq float64) float64 {
+	if len(np.Power) == 0 {
+		return 0
+	}
+	if len(np.Power) == 1 {
+		return np.Power[0]
+	}
+	x := freq / (float64(np.SampleRate) / 2) * float64(len(np.Power)-1)
+	if x >= float64(len(np.Power)-1) {
+		return np.Power[len(np.Power)-1]
+	}
+	k := int(x)
+	f := x - float64(k)
+	return np.Power[k] + f*(np.Power[k+1]-np.Power[k])
+}
+
+// noiseLearner averages the power spectra of frames into a NoiseProfile.
+type noiseLearner struct {
+	profile *NoiseProfile
+	sum     []float64
+	frames  int
+	scale   float64 // normalization of the power of a frame
+}
+
+func newNoiseLearner(sr beep.SampleRate, window []float64) *noiseLearner {
+	bins := len(window)/2 + 1
+	return &noiseLearner{
+		profile: &NoiseProfile{SampleRate: sr, Power: make([]float64, bins)},
+		sum:     make([]float64, bins),
+		scale:   windowScale(window),
+	}
+}
+
+// windowScale returns the normalization of the power of a frame windowed by window, 1 over the
+// sum of the squared window.
+func windowScale(window []float64) float64 {
+	var energy float64
+	for _, w := range window {
+		energy += w * w
+	}
+	return 1 / energy
+}
+
+func (l *noiseLearner) add(bins []complex128) {
+	l.frames++
+	for k, b := range bins {
+		l.sum[k] += real(b)*real(b) + imag(b)*imag(b)
+		l.profile.Power[k] = l.sum[k] * l.scale / float64(l.frames)
+	}
+}
+
+// LearnNoise returns the NoiseProfile of s, read until it's drained, measured with frames of size
+// samples. The SampleRate (sr) must match that of s. The noise should be a few seconds long,
+// longer noise gives a smoother profile. The profile of a marked segment of a recording can be
+// learned with beep.Take:
+//
+//	streamer.Seek(format.SampleRate.N(2 * time.Second))
+//	profile, err := effects.LearnNoise(beep.Take(format.SampleRate.N(time.Second), streamer), format.SampleRate, 2048)
+//
+// The size must be a power of two of at least 4, otherwise LearnNoise panics. LearnNoise returns an error if s
+// fails or is shorter than a single frame.
+func LearnNoise[S beep.Size, P beep.Point[S]](s beep.Streamer[S, P], sr beep.SampleRate, size int) (*NoiseProfile, error) {
+	if size < 4 || size&(size-1) != 0 {
+		panic(fmt.Errorf("effects: noise reduction: size not a power of two of at least 4: %d", size))
+	}
+
+	window := spectrum.Hann(size)
+	learner := newNoiseLearner(sr, window)
+	fft := spectrum.NewFFT[float64](size)
+	frame := make([]float64, size)
+	bins := make([]complex128, fft.Bins())
+
+	// the frames of each channel overlap by three quarters, so only the last frame is kept
+	var p P
+	hop := size / 4
+	last := make([][]float64, p.Count())
+	for c := range last {
+		last[c] = make([]float64, size)
+	}
+	filled := 0
+	buf := make([]P, 512)
+	for {
+		n, ok := s.Stream(buf)
+		if !ok {
+			break
+		}
+		for i := 0; i < n; {
+			m := n - i
+			if m > size-filled {
+				m = size - filled
+			}
+			points.Each(buf[i:i+m], func(ch []S) {
+				for c := range ch {
+					last[c][filled] = float64(ch[c])
+				}
+				filled++
+			})
+			i += m
+			if filled < size {
+				continue
+			}
+			for _, x := range last {
+				for k := range frame {
+					frame[k] = x[k] * window[k]
+				}
+				fft.Forward(bins, frame)
+				learner.add(bins)
+				copy(x, x[hop:])
+			}
+			filled -= hop
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("effects: noise reduction: %w", err)
+	}
+	if learner.frames == 0 {
+		return nil, errors.New("effects: noise reduction: noise shorter than a frame")
+	}
+	return learner.profile, nil
+}
+
+// NoiseReduction removes a constant background noise, such as hum or hiss, from the wrapped
+// Streamer. It splits the stream into frequencies with a short-time Fourier transform and turns
+// down those in which the noise, described by the Profile, dominates.
+//
+// The Profile is learned from a recording of the noise alone by LearnNoise, or by NoiseReduction
+// itself while Learn is set, for example while the speaker doesn't talk:
+//
+//	nr := effects.NewNoiseReduction(s, format.SampleRate, 2048)
+//	nr.Method = effects.WienerFilter
+//	nr.Reduction = 18
+//	nr.Smoothing = 50 * time.Millisecond
+//	nr.Learn = true // noise only for now
+//	// ...
+//	speaker.Lock()
+//	nr.Learn = false
+//	speaker.Unlock()
+//
+// The output lags behind the input by Latency samples. After the wrapped Streamer is drained,
+// NoiseReduction streams another Latency samples.
+//
+// If you're playing the NoiseReduction through the speaker, lock the speaker when modifying its
+// fields.
+type NoiseReduction[S beep.Size, P beep.Point[S]] struct {
+	// Profile is the noise to remove. Nil means no reduction.
+	Profile *NoiseProfile
+	// Learn makes the NoiseReduction learn a new Profile from the stream, which passes through
+	// unchanged in the meantime. Each time Learn is set, the learning starts over.
+	Learn bool
+	// Method is the rule by which the noise is turned down.
+	Method NoiseReductionMethod
+	// Reduction is the maximal attenuation of the noise in decibels [dB], for example 12. Higher
+	// values remove more noise, but also more of the signal. Zero disables the reduction.
+	Reduction float64
+	// Smoothing is the time constant with which the attenuation of each frequency follows the
+	// signal. Smoother attenuation leaves less musical noise, but blurs the onsets of sounds in
+	// noisy frequencies. Zero disables it. The attenuation is always smoothed across neighbouring
+	// frequencies, regardless of Smoothing.
+	Smoothing time.Duration
+
+	st       *spectrum.STFT[S, P]
+	sr       beep.SampleRate
+	size     int
+	window   []float64
+	scale    float64 // normalization of the power of a frame
+	learner  *noiseLearner
+	noiseFor *NoiseProfile // Profile for which noise was calculated
+	noise    []float64     // power of the noise per bin
+	gains    [][]float64   // smoothed gains per channel and bin
+	raw      []float64
+}
+
+// NewNoiseReduction returns a NoiseReduction of s with the sample rate sr, which analyzes frames of
+// size samples. Bigger frames separate the noise from the signal better, but smear transients and
+// add latency. At 44100 Hz, 2048 samples are a good default. The size must be a power of two of at
+// least 4, otherwise NewNoiseReduction panics.
+//
+// The NoiseReduction starts without a Profile, with the SpectralSubtraction method and without
+// reduction. It propagates s's errors through Err.
+func NewNoiseReduction[S beep.Size, P beep.Point[S]](s beep.Streamer[S, P], sr beep.SampleRate, size int) *NoiseReduction[S, P] {
+	if size < 4 || size&(size-1) != 0 {
+		panic(fmt.Errorf("effects: noise reduction: size not a power of two of at least 4: %d", size))
+	}
+	var p P
+	nr := &NoiseReduction[S, P]{
+		sr:     sr,
+		size:   size,
+		window: spectrum.Hann(size),
+		noise:  make([]float64, size/2+1),
+		gains:  make([][]float64, p.Count()),
+		raw:    make([]float64, size/2+1),
+	}
+	for c := range nr.gains {
+		nr.gains[c] = make([]float64, size/2+1)
+		for k := range nr.gains[c] {
+			nr.gains[c][k] = 1
+		}
+	}
+	nr.scale = windowScale(nr.window)
+	nr.st = spectrum.NewSTFT(s, size, size/4, nr.window, nr.process)
+	return nr
+}
+
+// Stream streams the wrapped Streamer with the noise reduced.
+func (nr *NoiseReduction[S, P]) Stream(samples []P) (n int, ok bool) {
+	if nr.Learn && nr.learner == nil {
+		nr.learner = newNoiseLearner(nr.sr, nr.window)
+		nr.Profile = nr.learner.profile
+	}
+	if !nr.Learn {
+		nr.learner = nil
+	}
+	return nr.st.Stream(samples)
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (nr *NoiseReduction[S, P]) Err() error {
+	return nr.st.Err()
+}
+
+// Latency returns the number of samples by which the output lags behind the input, which is the
+// frame size.
+func (nr *NoiseReduction[S, P]) Latency() int {
+	return nr.st.Latency()
+}
+
+// process attenuates the bins of a frame of a channel.
+func (nr *NoiseReduction[S, P]) process(channel int, bins []complex128) {
+	if nr.learner != nil {
+		nr.learner.add(bins)
+		return
+	}
+	if nr.Profile == nil || nr.Reduction <= 0 {
+		return
+	}
+	if nr.Profile != nr.noiseFor {
+		nr.noiseFor = nr.Profile
+		for k := range nr.noise {
+			nr.noise[k] = nr.Profile.at(spectrum.BinFrequency(nr.sr, nr.size, k))
+		}
+	}
+
+	floor := math.Pow(10, -nr.Reduction/20)
+	scale := nr.scale
+	for k, b := range bins {
+		power := (real(b)*real(b) + imag(b)*imag(b)) * scale
+		nr.raw[k] = 1
+		if power == 0 || nr.noise[k] == 0 {
+			continue
+		}
+		switch nr.Method {
+		case WienerFilter:
+			snr := math.Max(0, power/nr.noise[k]-1)
+			nr.raw[k] = snr / (1 + snr)
+		default:
+			nr.raw[k] = math.Sqrt(math.Max(0, 1-noiseOverSubtraction*nr.noise[k]/power))
+		}
+	}
+
+	// smooth the gains across neighbouring frequencies and over time, which keeps isolated bins
+	// from twinkling. A new frame comes every quarter of a frame, which is the rate of the
+	// smoothing over time.
+	smooth := 0.0
+	if nr.Smoothing > 0 {
+		frameRate := float64(nr.sr) / float64(nr.size/4)
+		smooth = math.Exp(-1 / (nr.Smoothing.Seconds() * frameRate))
+	}
+	gains := nr.gains[channel]
+	for k := range bins {
+		g := nr.raw[k]
+		if k > 0 && k < len(bins)-1 {
+			g = (nr.raw[k-1] + 2*g + nr.raw[k+1]) / 4
+		}
+		g = smooth*gains[k] + (1-smooth)*g
+		gains[k] = g
+		bins[k] *= complex(math.Max(floor, g), 0)
+	}
+}
@@ -0,0 +1,157 @@
+package effects_test
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+)
+
+// whiteNoise streams white noise with the standard deviation sigma forever.
+func whiteNoise(sigma float64, seed int64) beep.Streamer[float64, beep.Stereo[float64]] {
+	rng := rand.New(rand.NewSource(seed))
+	return beep.StreamerFunc[float64, beep.Stereo[float64]](func(samples []beep.Stereo[float64]) (n int, ok bool) {
+		for i := range samples {
+			samples[i] = beep.Stereo[float64]{rng.NormFloat64() * sigma, rng.NormFloat64() * sigma}
+		}
+		return len(samples), true
+	})
+}
+
+// rms returns the RMS level of the left channel of the second second of s.
+func rms(s beep.Streamer[float64, beep.Stereo[float64]], sr beep.SampleRate) float64 {
+	buf := make([]beep.Stereo[float64], sr)
+	s.Stream(buf)
+	s.Stream(buf)
+	var sum float64
+	for _, p := range buf {
+		sum += p[0] * p[0]
+	}
+	return math.Sqrt(sum / float64(len(buf)))
+}
+
+func TestLearnNoise(t *testing.T) {
+	const sr = 44100
+	profile, err := effects.LearnNoise[float64, beep.Stereo[float64]](beep.Take(sr, whiteNoise(0.1, 1)), sr, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.SampleRate != sr || len(profile.Power) != 513 {
+		t.Fatalf("got %v with %d bins", profile.SampleRate, len(profile.Power))
+	}
+	// white noise has the power of its variance in every bin, except at the edges
+	var sum float64
+	for _, p := range profile.Power[1:512] {
+		sum += p
+	}
+	if mean := sum / 511; math.Abs(mean-0.01) > 0.0005 {
+		t.Errorf("got the mean power %v, want 0.01", mean)
+	}
+}
+
+func TestLearnNoiseErrors(t *testing.T) {
+	if _, err := effects.LearnNoise[float64, beep.Stereo[float64]](constant(100, 0.1), 44100, 1024); err == nil {
+		t.Error("expected an error for noise shorter than a frame")
+	}
+	fail := errors.New("fail")
+	if _, err := effects.LearnNoise[float64, beep.Stereo[float64]](errStreamer{constant(0, 0), fail}, 44100, 1024); !errors.Is(err, fail) {
+		t.Errorf("got error %v, want %v", err, fail)
+	}
+}
+
+func TestNoiseReductionSizePanics(t *testing.T) {
+	for _, size := range []int{2, 1000} {
+		for name, f := range map[string]func(){
+			"LearnNoise": func() {
+				effects.LearnNoise[float64, beep.Stereo[float64]](constant(100, 0), 44100, size)
+			},
+			"NewNoiseReduction": func() {
+				effects.NewNoiseReduction[float64, beep.Stereo[float64]](constant(100, 0), 44100, size)
+			},
+		} {
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("%s with size %d: expected a panic", name, size)
+					}
+				}()
+				f()
+			}()
+		}
+	}
+}
+
+func TestNoiseReduction(t *testing.T) {
+	const sr = 44100
+	profile, err := effects.LearnNoise[float64, beep.Stereo[float64]](beep.Take(2*sr, whiteNoise(0.05, 1)), sr, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, method := range []effects.NoiseReductionMethod{effects.SpectralSubtraction, effects.WienerFilter} {
+		// the noise is turned down
+		nr := effects.NewNoiseReduction[float64, beep.Stereo[float64]](whiteNoise(0.05, 2), sr, 2048)
+		nr.Profile, nr.Method, nr.Reduction, nr.Smoothing = profile, method, 18, 20*time.Millisecond
+		if db := 20 * math.Log10(rms(nr, sr)/0.05); db > -10 {
+			t.Errorf("%v: got the noise at %.2f dB, want less than -10 dB", method, db)
+		}
+
+		// a tone well above the noise is kept
+		nr = effects.NewNoiseReduction[float64, beep.Stereo[float64]](sineStreamer(sr, 1000), sr, 2048)
+		nr.Profile, nr.Method, nr.Reduction, nr.Smoothing = profile, method, 18, 20*time.Millisecond
+		if db := 20 * math.Log10(rms(nr, sr)*math.Sqrt2); math.Abs(db) > 0.5 {
+			t.Errorf("%v: got the tone at %.2f dB, want 0 dB", method, db)
+		}
+	}
+}
+
+func TestNoiseReductionLearn(t *testing.T) {
+	const sr = 44100
+	nr := effects.NewNoiseReduction[float64, beep.Stereo[float64]](whiteNoise(0.05, 1), sr, 2048)
+	nr.Reduction = 18
+	nr.Learn = true
+
+	// the stream passes through while learning
+	if db := 20 * math.Log10(rms(nr, sr)/0.05); math.Abs(db) > 0.5 {
+		t.Errorf("got the noise at %.2f dB while learning, want 0 dB", db)
+	}
+	if nr.Profile == nil {
+		t.Fatal("no profile learned")
+	}
+
+	nr.Learn = false
+	if db := 20 * math.Log10(rms(nr, sr)/0.05); db > -10 {
+		t.Errorf("got the noise at %.2f dB after learning, want less than -10 dB", db)
+	}
+}
+
+func TestNoiseReductionDisabled(t *testing.T) {
+	data := make([]beep.Stereo[float64], 100)
+	for i := range data {
+		data[i] = beep.Stereo[float64]{math.Sin(float64(i)), math.Cos(float64(i))}
+	}
+	nr := effects.NewNoiseReduction[float64, beep.Stereo[float64]](sliceStreamer(append([]beep.Stereo[float64](nil), data...)), 44100, 64)
+	out := collectN(nr, -1)
+	if len(out) != len(data)+nr.Latency() {
+		t.Fatalf("got %d samples, want %d", len(out), len(data)+nr.Latency())
+	}
+	for i, p := range data {
+		q := out[i+nr.Latency()]
+		if math.Abs(p[0]-q[0]) > 1e-9 || math.Abs(p[1]-q[1]) > 1e-9 {
+			t.Fatalf("sample %d: got %v, want %v", i, q, p)
+		}
+	}
+}
+
+func TestNoiseReductionMethodString(t *testing.T) {
+	if s := effects.WienerFilter.String(); s != "WienerFilter" {
+		t.Errorf("got %q", s)
+	}
+	if s := effects.NoiseReductionMethod(5).String(); s != "NoiseReductionMethod(5)" {
+		t.Errorf("got %q", s)
+	}
+}
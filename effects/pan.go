@@ -1,32 +1,83 @@
 package effects
 
-import "github.com/faiface/beep"
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+)
+
+// panSmoothing is the time constant with which Pan and Balance follow changes of their position.
+const panSmoothing = 20 * time.Millisecond
+
+// PanLaw is the rule by which Pan splits a signal between the left and the right channel. The laws
+// differ in how loud the signal is in the center compared to the sides.
+type PanLaw int
+
+const (
+	// LinearPan moves a linear fraction of one channel into the other. The center leaves the
+	// signal unchanged and the sides add both channels up, so a mono signal gets 6 dB louder
+	// towards the sides.
+	LinearPan PanLaw = iota
+	// ConstantPowerPan puts the signal at -3 dB on each side in the center, which keeps its
+	// loudness the same at any position. It's the usual choice.
+	ConstantPowerPan
+	// CompromisePan puts the signal at -4.5 dB on each side in the center, between
+	// ConstantPowerPan and ConstantGainPan.
+	CompromisePan
+	// ConstantGainPan puts the signal at -6 dB on each side in the center, where the gains sum to
+	// one. It suits signals summed to mono later.
+	ConstantGainPan
+)
+
+// String returns the name of the pan law.
+func (l PanLaw) String() string {
+	switch l {
+	case LinearPan:
+		return "LinearPan"
+	case ConstantPowerPan:
+		return "ConstantPowerPan"
+	case CompromisePan:
+		return "CompromisePan"
+	case ConstantGainPan:
+		return "ConstantGainPan"
+	}
+	return fmt.Sprintf("PanLaw(%d)", int(l))
+}
 
 // Pan balances the wrapped Streamer between the left and the right channel. The Pan field value of
 // -1 means that both original channels go through the left channel. The value of +1 means the same
-// for the right channel. The value of 0 changes nothing.
+// for the right channel. The value of 0 changes nothing with LinearPan.
+//
+// With any Law other than LinearPan, the channels are mixed down to mono and the mono signal is
+// split between the left and the right channel according to the Law. This is the way to place a
+// mono source in a stereo mix.
+//
+// If SampleRate is set, changes of Pan are smoothed over a few milliseconds, which avoids zipper
+// noise when it's animated.
 type Pan[S beep.Size, P beep.Point[S]] struct {
-	Streamer beep.Streamer[S, P]
-	Pan      float64
+	Streamer   beep.Streamer[S, P]
+	Pan        float64
+	Law        PanLaw
+	SampleRate beep.SampleRate
+
+	pos     float64 // smoothed Pan
+	started bool
 }
 
 // Stream streams the wrapped Streamer balanced by Pan.
 func (p *Pan[S, P]) Stream(samples []P) (n int, ok bool) {
 	n, ok = p.Streamer.Stream(samples)
-	switch {
-	case p.Pan < 0:
-		for i := range samples[:n] {
-			r := float64(samples[i][1])
-			samples[i][0] += S(-p.Pan * r)
-			samples[i][1] -= S(-p.Pan * r)
-		}
-	case p.Pan > 0:
-		for i := range samples[:n] {
-			l := float64(samples[i][0])
-			samples[i][0] -= S(p.Pan * l)
-			samples[i][1] += S(p.Pan * l)
-		}
+	if !p.started {
+		p.pos, p.started = p.Pan, true
 	}
+	smooth := timeCoef(panSmoothing, p.SampleRate)
+	points.Each(samples[:n], func(ch []S) {
+		p.pos = smooth*p.pos + (1-smooth)*p.Pan
+		pan(ch, p.pos, p.Law)
+	})
 	return n, ok
 }
 
@@ -34,3 +85,77 @@ func (p *Pan[S, P]) Stream(samples []P) (n int, ok bool) {
 func (p *Pan[S, P]) Err() error {
 	return p.Streamer.Err()
 }
+
+// pan splits the signal of the first two channels of a sample between them according to the
+// position x between -1 (left) and 1 (right) and the law, as described by Pan.
+func pan[S beep.Size](ch []S, x float64, law PanLaw) {
+	if len(ch) < 2 {
+		return
+	}
+	x = math.Max(-1, math.Min(1, x))
+
+	if law == LinearPan {
+		switch {
+		case x < 0:
+			r := -x * float64(ch[1])
+			ch[0] += S(r)
+			ch[1] -= S(r)
+		case x > 0:
+			l := x * float64(ch[0])
+			ch[0] -= S(l)
+			ch[1] += S(l)
+		}
+		return
+	}
+
+	t := (x + 1) / 2
+	l, r := 1-t, t // ConstantGainPan
+	switch law {
+	case ConstantPowerPan:
+		l, r = math.Cos(t*math.Pi/2), math.Sin(t*math.Pi/2)
+	case CompromisePan:
+		l, r = math.Sqrt(l*math.Cos(t*math.Pi/2)), math.Sqrt(r*math.Sin(t*math.Pi/2))
+	}
+	mono := (float64(ch[0]) + float64(ch[1])) / 2
+	ch[0], ch[1] = S(mono*l), S(mono*r)
+}
+
+// Balance adjusts the balance of a stereo Streamer by turning down one of its channels. The
+// Balance field value of -1 silences the right channel, +1 silences the left channel and 0
+// changes nothing. Unlike Pan, it never moves the signal from one channel to the other, so the
+// stereo image stays intact.
+//
+// If SampleRate is set, changes of Balance are smoothed over a few milliseconds, which avoids
+// zipper noise when it's animated.
+type Balance[S beep.Size, P beep.Point[S]] struct {
+	Streamer   beep.Streamer[S, P]
+	Balance    float64
+	SampleRate beep.SampleRate
+
+	pos     float64 // smoothed Balance
+	started bool
+}
+
+// Stream streams the wrapped Streamer with adjusted balance.
+func (b *Balance[S, P]) Stream(samples []P) (n int, ok bool) {
+	n, ok = b.Streamer.Stream(samples)
+	if !b.started {
+		b.pos, b.started = b.Balance, true
+	}
+	smooth := timeCoef(panSmoothing, b.SampleRate)
+	points.Each(samples[:n], func(ch []S) {
+		b.pos = smooth*b.pos + (1-smooth)*b.Balance
+		if len(ch) < 2 {
+			return
+		}
+		x := math.Max(-1, math.Min(1, b.pos))
+		ch[0] *= S(math.Min(1, 1-x))
+		ch[1] *= S(math.Min(1, 1+x))
+	})
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (b *Balance[S, P]) Err() error {
+	return b.Streamer.Err()
+}
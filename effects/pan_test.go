@@ -0,0 +1,89 @@
+package effects_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+)
+
+func TestPanLaws(t *testing.T) {
+	db := func(x float64) float64 { return 20 * math.Log10(x) }
+	tests := []struct {
+		law    effects.PanLaw
+		center float64 // level of each channel in the center in dB
+	}{
+		{effects.LinearPan, 0},
+		{effects.ConstantPowerPan, -3.01},
+		{effects.CompromisePan, -4.52},
+		{effects.ConstantGainPan, -6.02},
+	}
+	for _, test := range tests {
+		level := func(pos float64) beep.Stereo[float64] {
+			p := &effects.Pan[float64, beep.Stereo[float64]]{Streamer: constant(1, 0.5), Pan: pos, Law: test.law}
+			got := collectN(p, -1)[0]
+			return beep.Stereo[float64]{got[0] / 0.5, got[1] / 0.5}
+		}
+		if c := level(0); math.Abs(db(c[0])-test.center) > 0.01 || math.Abs(c[0]-c[1]) > 1e-12 {
+			t.Errorf("%v: center: got %.2f dB, %.2f dB, want %.2f dB", test.law, db(c[0]), db(c[1]), test.center)
+		}
+		left, right := level(-1), level(1)
+		want := 1.0
+		if test.law == effects.LinearPan {
+			want = 2 // both channels add up
+		}
+		if math.Abs(left[0]-want) > 1e-9 || math.Abs(left[1]) > 1e-12 || math.Abs(right[0]) > 1e-12 || math.Abs(right[1]-want) > 1e-9 {
+			t.Errorf("%v: got %v on the left and %v on the right", test.law, left, right)
+		}
+		if test.law == effects.ConstantPowerPan {
+			for _, pos := range []float64{-0.7, -0.2, 0.4, 0.9} {
+				g := level(pos)
+				if power := g[0]*g[0] + g[1]*g[1]; math.Abs(power-1) > 1e-9 {
+					t.Errorf("%v: position %v: got power %v, want 1", test.law, pos, power)
+				}
+			}
+		}
+	}
+}
+
+func TestPanSmoothing(t *testing.T) {
+	p := &effects.Pan[float64, beep.Stereo[float64]]{
+		Streamer:   constant(-1, 1),
+		Pan:        -1,
+		Law:        effects.ConstantPowerPan,
+		SampleRate: 44100,
+	}
+	buf := make([]beep.Stereo[float64], 4410)
+	p.Stream(buf)
+	p.Pan = 1
+	p.Stream(buf)
+	for i := 1; i < len(buf); i++ {
+		if step := math.Abs(buf[i][0] - buf[i-1][0]); step > 0.01 {
+			t.Fatalf("sample %d: jumps by %v", i, step)
+		}
+	}
+	p.Stream(buf)
+	if buf[len(buf)-1][0] > 0.001 {
+		t.Errorf("the position didn't settle: got %v", buf[len(buf)-1])
+	}
+}
+
+func TestBalance(t *testing.T) {
+	for _, test := range []struct {
+		balance float64
+		want    beep.Stereo[float64]
+	}{
+		{0, beep.Stereo[float64]{0.5, 0.25}},
+		{-0.5, beep.Stereo[float64]{0.5, 0.125}},
+		{1, beep.Stereo[float64]{0, 0.25}},
+	} {
+		b := &effects.Balance[float64, beep.Stereo[float64]]{
+			Streamer: sliceStreamer([]beep.Stereo[float64]{{0.5, 0.25}}),
+			Balance:  test.balance,
+		}
+		if got := collectN(b, -1)[0]; got != test.want {
+			t.Errorf("balance %v: got %v, want %v", test.balance, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package effects
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+)
+
+// ParametricEqualizer is a parametric equalizer in the form used by Equalizer APO and the
+// headphone corrections published by AutoEQ:
+//
+//	Preamp: -6.2 dB
+//	Filter 1: ON LSC Fc 105 Hz Gain 5.5 dB Q 0.70
+//	Filter 2: ON PK Fc 2900 Hz Gain -3.2 dB Q 2.10
+//	Filter 3: ON HSC Fc 10000 Hz Gain -2.0 dB Q 0.70
+//
+// Use ParseParametricEqualizer to read it and NewParametricEqualizer to apply it to a Streamer.
+type ParametricEqualizer struct {
+	// Preamp is the gain applied to the whole signal in decibels [dB]. It's usually negative to
+	// leave headroom for the boosts of the filters.
+	Preamp float64
+
+	// Filters are the enabled filters, in order.
+	Filters []Biquad
+}
+
+// parametricTypes maps the filter types of the text format to FilterTypes. LS and HS are missing
+// on purpose: they are shelves with a fixed slope around a center frequency, which LowShelf and
+// HighShelf don't implement.
+var parametricTypes = map[string]FilterType{
+	"PK":  Peaking,
+	"LSC": LowShelf,
+	"HSC": HighShelf,
+	"LP":  LowPass,
+	"LPQ": LowPass,
+	"HP":  HighPass,
+	"HPQ": HighPass,
+	"BP":  BandPass,
+	"NO":  Notch,
+	"AP":  AllPass,
+}
+
+// ParseParametricEqualizer reads a parametric equalizer in the Equalizer APO text format from r.
+//
+// Preamp lines add up. Filter lines which are OFF are skipped. Supported filter types are PK, LSC,
+// HSC, LP, LPQ, HP, HPQ, BP, NO and AP, with the parameters Fc (Hz), Gain (dB), Q and BW Oct. A
+// missing Q means 1/√2. The fixed-slope shelves LS and HS are not supported and result in an
+// error, as do lines with any other content, except for empty lines and lines starting with #,
+// which are ignored.
+func ParseParametricEqualizer(r io.Reader) (ParametricEqualizer, error) {
+	var eq ParametricEqualizer
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		colon := strings.IndexByte(text, ':')
+		if colon < 0 {
+			return ParametricEqualizer{}, fmt.Errorf("effects: parametric equalizer: line %d: missing colon", line)
+		}
+		command, args := strings.TrimSpace(text[:colon]), strings.Fields(text[colon+1:])
+
+		switch {
+		case command == "Preamp":
+			if len(args) == 0 || len(args) > 2 || (len(args) == 2 && !strings.EqualFold(args[1], "dB")) {
+				return ParametricEqualizer{}, fmt.Errorf("effects: parametric equalizer: line %d: invalid preamp", line)
+			}
+			gain, err := strconv.ParseFloat(args[0], 64)
+			if err != nil {
+				return ParametricEqualizer{}, fmt.Errorf("effects: parametric equalizer: line %d: invalid preamp: %w", line, err)
+			}
+			eq.Preamp += gain
+
+		case command == "Filter" || strings.HasPrefix(command, "Filter "):
+			b, on, err := parseParametricFilter(args)
+			if err != nil {
+				return ParametricEqualizer{}, fmt.Errorf("effects: parametric equalizer: line %d: %w", line, err)
+			}
+			if on {
+				eq.Filters = append(eq.Filters, b)
+			}
+
+		default:
+			return ParametricEqualizer{}, fmt.Errorf("effects: parametric equalizer: line %d: unsupported command %q", line, command)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return ParametricEqualizer{}, fmt.Errorf("effects: parametric equalizer: %w", err)
+	}
+	return eq, nil
+}
+
+// parseParametricFilter parses the arguments of a filter line, such as
+// "ON PK Fc 105 Hz Gain 3.2 dB Q 0.70".
+func parseParametricFilter(args []string) (b Biquad, on bool, err error) {
+	if len(args) < 2 {
+		return Biquad{}, false, fmt.Errorf("incomplete filter")
+	}
+	switch strings.ToUpper(args[0]) {
+	case "ON":
+		on = true
+	case "OFF":
+		on = false
+	default:
+		return Biquad{}, false, fmt.Errorf("expected ON or OFF, got %q", args[0])
+	}
+	typ, ok := parametricTypes[strings.ToUpper(args[1])]
+	if !ok {
+		return Biquad{}, false, fmt.Errorf("unsupported filter type %q", args[1])
+	}
+	b.Type = typ
+
+	// number parses the value following the parameter at args[i] and skips the optional unit
+	number := func(i int, unit string) (float64, int, error) {
+		if i+1 >= len(args) {
+			return 0, i, fmt.Errorf("missing value of %s", args[i])
+		}
+		x, err := strconv.ParseFloat(args[i+1], 64)
+		if err != nil {
+			return 0, i, fmt.Errorf("invalid value of %s: %w", args[i], err)
+		}
+		i += 2
+		if unit != "" && i < len(args) && strings.EqualFold(args[i], unit) {
+			i++
+		}
+		return x, i, nil
+	}
+
+	hasFreq := false
+	for i := 2; i < len(args); {
+		var err error
+		switch strings.ToUpper(args[i]) {
+		case "FC":
+			b.Freq, i, err = number(i, "Hz")
+			hasFreq = true
+		case "GAIN":
+			b.Gain, i, err = number(i, "dB")
+		case "Q":
+			b.Q, i, err = number(i, "")
+		case "BW":
+			if i+1 >= len(args) || !strings.EqualFold(args[i+1], "Oct") {
+				return Biquad{}, false, fmt.Errorf("only BW Oct is supported")
+			}
+			var bw float64
+			bw, i, err = number(i+1, "")
+			b.Q = BandwidthToQ(bw)
+		default:
+			return Biquad{}, false, fmt.Errorf("unsupported filter parameter %q", args[i])
+		}
+		if err != nil {
+			return Biquad{}, false, err
+		}
+	}
+	if !hasFreq {
+		return Biquad{}, false, fmt.Errorf("missing Fc")
+	}
+	return b, on, nil
+}
+
+// NewParametricEqualizer returns a Streamer which applies the preamp and the filters of eq to s.
+// The SampleRate (sr) must match that of the Streamer.
+//
+//	f, _ := os.Open("Sennheiser HD 650 ParametricEQ.txt")
+//	eq, err := effects.ParseParametricEqualizer(f)
+//	// ...
+//	corrected := effects.NewParametricEqualizer(s, format.SampleRate, eq)
+//
+// The returned Streamer propagates s's errors through Err.
+func NewParametricEqualizer[S beep.Size, P beep.Point[S]](s beep.Streamer[S, P], sr beep.SampleRate, eq ParametricEqualizer) beep.Streamer[S, P] {
+	return &parametricEqualizer[S, P]{
+		filter: NewFilter(s, sr, eq.Filters...),
+		preamp: S(math.Pow(10, eq.Preamp/20)),
+	}
+}
+
+type parametricEqualizer[S beep.Size, P beep.Point[S]] struct {
+	filter *Filter[S, P]
+	preamp S
+}
+
+func (p *parametricEqualizer[S, P]) Stream(samples []P) (n int, ok bool) {
+	n, ok = p.filter.Stream(samples)
+	points.Each(samples[:n], func(ch []S) {
+		for c := range ch {
+			ch[c] *= p.preamp
+		}
+	})
+	return n, ok
+}
+
+func (p *parametricEqualizer[S, P]) Err() error {
+	return p.filter.Err()
+}
@@ -0,0 +1,67 @@
+package effects_test
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+)
+
+func TestParseParametricEqualizer(t *testing.T) {
+	const text = `# AutoEQ correction
+Preamp: -6.4 dB
+Filter 1: ON LSC Fc 105 Hz Gain 5.5 dB Q 0.70
+Filter 2: ON PK Fc 2900 Hz Gain -3.2 dB Q 2.10
+Filter 3: OFF PK Fc 4000 Hz Gain 9 dB Q 1
+Filter 4: ON HSC Fc 10000 Hz Gain -2.0 dB Q 0.70
+
+Filter: ON HP Fc 20 Hz
+Filter 6: ON PK Fc 500 Hz Gain 1 dB BW Oct 1
+`
+	got, err := effects.ParseParametricEqualizer(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := effects.ParametricEqualizer{
+		Preamp: -6.4,
+		Filters: []effects.Biquad{
+			{Type: effects.LowShelf, Freq: 105, Gain: 5.5, Q: 0.7},
+			{Type: effects.Peaking, Freq: 2900, Gain: -3.2, Q: 2.1},
+			{Type: effects.HighShelf, Freq: 10000, Gain: -2, Q: 0.7},
+			{Type: effects.HighPass, Freq: 20},
+			{Type: effects.Peaking, Freq: 500, Gain: 1, Q: effects.BandwidthToQ(1)},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	for _, bad := range []string{
+		"Preamp: loud",
+		"Filter 1: ON XX Fc 100 Hz",
+		"Filter 1: ON LS Fc 100 Hz Gain 3 dB",
+		"Filter 1: ON HS Fc 8000 Hz Gain -3 dB",
+		"Filter 1: ON PK Gain 3 dB Q 1",
+		"Filter 1: MAYBE PK Fc 100 Hz",
+		"Channel: L",
+	} {
+		if _, err := effects.ParseParametricEqualizer(strings.NewReader(bad)); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
+
+func TestParametricEqualizerStream(t *testing.T) {
+	const sr = beep.SampleRate(44100)
+	eq := effects.ParametricEqualizer{
+		Preamp:  -6,
+		Filters: []effects.Biquad{{Type: effects.Peaking, Freq: 1000, Gain: 6, Q: 1}},
+	}
+	l, r := amplitude(effects.NewParametricEqualizer(sineStreamer(sr, 1000), sr, eq), sr)
+	if math.Abs(dB(l)) > 0.05 || math.Abs(dB(r)) > 0.05 {
+		t.Errorf("got %.2f dB, %.2f dB, want 0 dB", dB(l), dB(r))
+	}
+}
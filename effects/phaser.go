@@ -0,0 +1,81 @@
+package effects
+
+import (
+	"math"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+)
+
+// Phaser streams the wrapped Streamer through a chain of all-pass filters and mixes the result
+// with the original. The phase shift of the chain cancels some frequencies, and as the LFO sweeps
+// the filters, the notches move up and down the spectrum.
+//
+// Every two Stages make one notch. The break frequency of the filters sweeps exponentially between
+// MinFreq and MaxFreq, centered between them; an LFO.Depth of 1 covers the whole range.
+//
+// If you're playing the Phaser through the speaker, lock the speaker when modifying its fields.
+type Phaser[S beep.Size, P beep.Point[S]] struct {
+	Streamer   beep.Streamer[S, P]
+	SampleRate beep.SampleRate
+	LFO        LFO
+
+	// Stages is the number of first order all-pass filters, usually 4, 6 or 8.
+	Stages int
+	// MinFreq and MaxFreq in Hertz [Hz] are the range of the sweep, for example 200 and 2000.
+	MinFreq, MaxFreq float64
+	// Feedback between -1 and 1 is the level of the output of the filters fed back into them,
+	// which sharpens the notches.
+	Feedback float64
+	// Mix between 0 and 1 is the level of the filtered signal against the original. 0.5 makes the
+	// deepest notches.
+	Mix float64
+
+	states [][]phaserState
+	last   []float64 // output of the filters, per channel
+}
+
+// phaserState is the state of a first order all-pass filter.
+type phaserState struct {
+	x1, y1 float64
+}
+
+// Stream streams the wrapped Streamer with phasing.
+func (p *Phaser[S, P]) Stream(samples []P) (n int, ok bool) {
+	n, ok = p.Streamer.Stream(samples)
+	if p.states == nil || len(p.states[0]) != p.Stages {
+		var pt P
+		p.states = make([][]phaserState, pt.Count())
+		for c := range p.states {
+			p.states[c] = make([]phaserState, p.Stages)
+		}
+		p.last = make([]float64, pt.Count())
+	}
+
+	ratio := math.Log(p.MaxFreq / p.MinFreq)
+	points.Each(samples[:n], func(ch []S) {
+		for c := range ch {
+			freq := p.MinFreq * math.Exp(ratio*(1+p.LFO.at(c))/2)
+			t := math.Tan(math.Pi * math.Min(freq, 0.49*float64(p.SampleRate)) / float64(p.SampleRate))
+			a := (t - 1) / (t + 1)
+
+			x := float64(ch[c])
+			y := x + p.Feedback*p.last[c]
+			for i := range p.states[c] {
+				s := &p.states[c][i]
+				out := a*y + s.x1 - a*s.y1
+				s.x1, s.y1 = y, undenormal(out)
+				y = out
+			}
+			p.last[c] = y
+			ch[c] = S((1-p.Mix)*x + p.Mix*y)
+		}
+		p.LFO.advance(p.SampleRate)
+	})
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (p *Phaser[S, P]) Err() error {
+	return p.Streamer.Err()
+}
@@ -0,0 +1,39 @@
+package effects_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+)
+
+func TestPhaserNotches(t *testing.T) {
+	const sr = beep.SampleRate(44100)
+	level := func(freq float64) float64 {
+		p := &effects.Phaser[float64, beep.Stereo[float64]]{
+			Streamer:   sineStreamer(sr, freq),
+			SampleRate: sr,
+			Stages:     4,
+			MinFreq:    200,
+			MaxFreq:    2000,
+			Mix:        0.5,
+		}
+		l, _ := amplitude(p, sr)
+		return l
+	}
+
+	// without modulation, the filters break at √(200·2000) Hz and four of them shift the phase by
+	// 180° at tan(22.5°) and 540° at tan(67.5°) times that
+	fc := math.Sqrt(200 * 2000)
+	for _, freq := range []float64{fc * math.Tan(math.Pi/8), fc * math.Tan(3*math.Pi/8)} {
+		if l := level(freq); l > 0.05 {
+			t.Errorf("%.0f Hz: got level %v, want a notch", freq, l)
+		}
+	}
+	for _, freq := range []float64{20, fc} {
+		if l := level(freq); l < 0.9 {
+			t.Errorf("%.0f Hz: got level %v, want 1", freq, l)
+		}
+	}
+}
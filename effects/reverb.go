@@ -0,0 +1,220 @@
+package effects
+
+import (
+	"math"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+)
+
+// Tunings of the Freeverb comb and all-pass filters in samples at 44100 Hz. The right channel uses
+// the same tunings plus reverbSpread, which decorrelates the channels.
+var (
+	reverbCombTunings    = []int{1116, 1188, 1277, 1356, 1422, 1491, 1557, 1617}
+	reverbAllPassTunings = []int{556, 441, 341, 225}
+)
+
+const (
+	reverbSpread    = 23
+	reverbInputGain = 0.015
+	reverbScaleWet  = 3
+)
+
+// tailSilence is the level below which the tail of an effect is considered decayed (-100 dB).
+const tailSilence = 1e-5
+
+// Reverb simulates the reflections of a room using the Freeverb algorithm by Jezar at
+// Dreampoint, a Schroeder-Moorer network of eight parallel low-pass feedback comb filters followed
+// by four series all-pass filters per channel.
+//
+// After the wrapped Streamer is drained, Reverb keeps streaming the tail of the reverberation until
+// it decays to silence.
+//
+//	verb := &effects.Reverb[float64, beep.Stereo[float64]]{
+//		Streamer:   s,
+//		SampleRate: format.SampleRate,
+//		RoomSize:   0.8,
+//		Damping:    0.5,
+//		Wet:        0.3,
+//		Dry:        1,
+//		Width:      1,
+//		PreDelay:   20 * time.Millisecond,
+//	}
+//
+// If you're playing the Reverb through the speaker, lock the speaker when modifying its fields.
+// SampleRate must not change after streaming started.
+type Reverb[S beep.Size, P beep.Point[S]] struct {
+	Streamer   beep.Streamer[S, P]
+	SampleRate beep.SampleRate
+
+	// RoomSize between 0 and 1 sets the length of the tail.
+	RoomSize float64
+	// Damping between 0 and 1 sets how fast high frequencies decay compared to low ones.
+	Damping float64
+	// Wet is the level of the reverberation, usually between 0 and 1.
+	Wet float64
+	// Dry is the level of the original signal, 1 leaves it unchanged.
+	Dry float64
+	// Width between 0 (mono) and 1 (wide) sets the stereo width of the reverberation.
+	Width float64
+	// PreDelay delays the reverberation against the original signal, which makes the room sound
+	// bigger without a longer tail.
+	PreDelay time.Duration
+
+	combs    [2][]reverbComb
+	allPass  [2][]reverbAllPass
+	pre      []float64 // pre-delay line
+	prePos   int
+	drained  bool
+	quiet    int // consecutive samples of silent output after the Streamer is drained
+	maxDelay int // length of the longest comb, after which a silent output means a silent network
+}
+
+// Stream streams the wrapped Streamer with reverberation.
+func (r *Reverb[S, P]) Stream(samples []P) (n int, ok bool) {
+	if r.combs[0] == nil {
+		r.init()
+	}
+	if l := r.SampleRate.N(r.PreDelay); l != len(r.pre) {
+		r.pre = make([]float64, l)
+		r.prePos = 0
+	}
+
+	for n < len(samples) {
+		if !r.drained {
+			sn, sok := r.Streamer.Stream(samples[n:])
+			if !sok {
+				r.drained = true
+				if r.Streamer.Err() != nil {
+					// no tail on error
+					r.quiet = r.maxDelay + len(r.pre) + 1
+				}
+				continue
+			}
+			r.process(samples[n : n+sn])
+			n += sn
+			continue
+		}
+		if r.quiet > r.maxDelay+len(r.pre) {
+			break
+		}
+		// stream the tail sample by sample, so it stops right after it decays
+		var zero P
+		samples[n] = zero
+		r.process(samples[n : n+1])
+		var level float64
+		for c := 0; c < samples[n].Count(); c++ {
+			level = math.Max(level, math.Abs(float64(samples[n].Get(c))))
+		}
+		if level < tailSilence {
+			r.quiet++
+		} else {
+			r.quiet = 0
+		}
+		n++
+	}
+	return n, n > 0
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (r *Reverb[S, P]) Err() error {
+	return r.Streamer.Err()
+}
+
+func (r *Reverb[S, P]) init() {
+	scale := float64(r.SampleRate) / 44100
+	for c := range r.combs {
+		spread := c * reverbSpread
+		r.combs[c] = make([]reverbComb, len(reverbCombTunings))
+		for i, t := range reverbCombTunings {
+			l := int(float64(t+spread) * scale)
+			r.combs[c][i].buf = make([]float64, l)
+			if l > r.maxDelay {
+				r.maxDelay = l
+			}
+		}
+		r.allPass[c] = make([]reverbAllPass, len(reverbAllPassTunings))
+		for i, t := range reverbAllPassTunings {
+			r.allPass[c][i].buf = make([]float64, int(float64(t+spread)*scale))
+		}
+	}
+}
+
+func (r *Reverb[S, P]) process(samples []P) {
+	feedback := r.RoomSize*0.28 + 0.7
+	damp := r.Damping * 0.4
+	wet := r.Wet * reverbScaleWet
+	wet1 := wet * (r.Width/2 + 0.5)
+	wet2 := wet * (1 - r.Width) / 2
+	dry := r.Dry
+
+	points.Each(samples, func(ch []S) {
+		var in float64
+		for _, x := range ch {
+			in += float64(x)
+		}
+		in *= reverbInputGain
+		if len(r.pre) > 0 {
+			in, r.pre[r.prePos] = r.pre[r.prePos], in
+			r.prePos = (r.prePos + 1) % len(r.pre)
+		}
+
+		var out [2]float64
+		for c := range out {
+			for i := range r.combs[c] {
+				out[c] += r.combs[c][i].process(in, feedback, damp)
+			}
+			for i := range r.allPass[c] {
+				out[c] = r.allPass[c][i].process(out[c])
+			}
+		}
+
+		switch len(ch) {
+		case 1:
+			ch[0] = S(out[0]*wet + float64(ch[0])*dry)
+		default:
+			l := out[0]*wet1 + out[1]*wet2
+			rr := out[1]*wet1 + out[0]*wet2
+			ch[0] = S(l + float64(ch[0])*dry)
+			ch[1] = S(rr + float64(ch[1])*dry)
+		}
+	})
+}
+
+// reverbComb is a feedback comb filter with a one-pole low-pass filter in the feedback path.
+type reverbComb struct {
+	buf   []float64
+	pos   int
+	store float64 // state of the low-pass filter
+}
+
+func (c *reverbComb) process(x, feedback, damp float64) float64 {
+	y := c.buf[c.pos]
+	c.store = undenormal(y*(1-damp) + c.store*damp)
+	c.buf[c.pos] = x + c.store*feedback
+	c.pos = (c.pos + 1) % len(c.buf)
+	return y
+}
+
+// reverbAllPass is the Schroeder all-pass filter used by Freeverb.
+type reverbAllPass struct {
+	buf []float64
+	pos int
+}
+
+func (a *reverbAllPass) process(x float64) float64 {
+	b := a.buf[a.pos]
+	a.buf[a.pos] = undenormal(x + b*0.5)
+	a.pos = (a.pos + 1) % len(a.buf)
+	return b - x
+}
+
+// undenormal flushes values too small to matter to zero. Decaying feedback loops would otherwise
+// end up computing with denormal numbers, which are very slow.
+func undenormal(x float64) float64 {
+	if math.Abs(x) < 1e-30 {
+		return 0
+	}
+	return x
+}
@@ -0,0 +1,80 @@
+package effects_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+)
+
+func TestReverbTail(t *testing.T) {
+	const sr = beep.SampleRate(44100)
+	for _, room := range []float64{0.2, 0.9} {
+		r := &effects.Reverb[float64, beep.Stereo[float64]]{
+			Streamer:   constant(100, 0.9),
+			SampleRate: sr,
+			RoomSize:   room,
+			Damping:    0.5,
+			Wet:        0.5,
+			Dry:        1,
+			Width:      1,
+			PreDelay:   10 * time.Millisecond,
+		}
+		got := collectN(r, 60*int(sr))
+		if len(got) >= 60*int(sr) {
+			t.Fatalf("room %v: the tail doesn't end", room)
+		}
+		if len(got) < int(sr)/2 {
+			t.Errorf("room %v: the tail is only %d samples long", room, len(got))
+		}
+		for i, p := range got[:100] {
+			if i < sr.N(10*time.Millisecond) && p != [2]float64{0.9, 0.9} {
+				t.Fatalf("room %v: sample %d: wet signal before the pre-delay: %v", room, i, p)
+			}
+		}
+		var tail float64
+		for _, p := range got[len(got)/2:] {
+			tail = math.Max(tail, math.Abs(p[0]))
+		}
+		if tail == 0 {
+			t.Errorf("room %v: silent tail", room)
+		}
+		if last := got[len(got)-1]; math.Abs(last[0]) > 1e-5 || math.Abs(last[1]) > 1e-5 {
+			t.Errorf("room %v: the tail ends at %v", room, last)
+		}
+	}
+}
+
+func TestReverbDecaysFasterInSmallRooms(t *testing.T) {
+	const sr = beep.SampleRate(22050)
+	length := func(room float64) int {
+		return len(collectN(&effects.Reverb[float64, beep.Stereo[float64]]{
+			Streamer:   constant(10, 1),
+			SampleRate: sr,
+			RoomSize:   room,
+			Wet:        1,
+		}, -1))
+	}
+	if small, big := length(0.1), length(0.95); small >= big {
+		t.Errorf("small room tail %d samples, big room tail %d samples", small, big)
+	}
+}
+
+func TestReverbError(t *testing.T) {
+	r := &effects.Reverb[float64, beep.Stereo[float64]]{
+		Streamer:   errStreamer{constant(100, 0.9), errors.New("fail")},
+		SampleRate: 44100,
+		RoomSize:   0.5,
+		Damping:    0.5,
+		Wet:        0.5,
+		Dry:        1,
+		Width:      1,
+	}
+	// no tail after an error
+	if got := collectN(r, 1000000); len(got) != 100 {
+		t.Errorf("got %d samples, want 100", len(got))
+	}
+}
@@ -1,6 +1,9 @@
 package effects
 
-import "github.com/faiface/beep"
+import (
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+)
 
 // Swap swaps the left and right channel of the wrapped Streamer.
 //
@@ -15,9 +18,12 @@ type swap[S beep.Size, P beep.Point[S]] struct {
 
 func (s *swap[S, P]) Stream(samples []P) (n int, ok bool) {
 	n, ok = s.Streamer.Stream(samples)
-	for i := range samples[:n] {
-		samples[i][0], samples[i][1] = samples[i][1], samples[i][0]
-	}
+	points.Each(samples[:n], func(ch []S) {
+		if len(ch) < 2 {
+			return
+		}
+		ch[0], ch[1] = ch[1], ch[0]
+	})
 	return n, ok
 }
 
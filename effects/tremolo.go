@@ -0,0 +1,68 @@
+package effects
+
+import (
+	"math"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+)
+
+// Tremolo periodically turns the volume of the wrapped Streamer down and up.
+//
+// The volume works like in Volume: the gain is Base raised to the power of the volume. The LFO
+// moves the volume between Volume-LFO.Depth and Volume+LFO.Depth, so with a Base of 2, a Volume
+// of -1 and an LFO.Depth of 1, the gain moves between 1/4 and 1.
+//
+// If you're playing the Tremolo through the speaker, lock the speaker when modifying its fields.
+type Tremolo[S beep.Size, P beep.Point[S]] struct {
+	Streamer   beep.Streamer[S, P]
+	SampleRate beep.SampleRate
+	LFO        LFO
+	Base       float64
+	Volume     float64
+}
+
+// Stream streams the wrapped Streamer with tremolo.
+func (t *Tremolo[S, P]) Stream(samples []P) (n int, ok bool) {
+	n, ok = t.Streamer.Stream(samples)
+	points.Each(samples[:n], func(ch []S) {
+		for c := range ch {
+			ch[c] *= S(math.Pow(t.Base, t.Volume+t.LFO.at(c)))
+		}
+		t.LFO.advance(t.SampleRate)
+	})
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (t *Tremolo[S, P]) Err() error {
+	return t.Streamer.Err()
+}
+
+// AutoPan periodically moves the wrapped Streamer between the left and the right channel.
+//
+// The panning works like in Pan, according to the Law. The LFO moves the Pan value between
+// -LFO.Depth and LFO.Depth. StereoPhase of the LFO has no effect.
+//
+// If you're playing the AutoPan through the speaker, lock the speaker when modifying its fields.
+type AutoPan[S beep.Size, P beep.Point[S]] struct {
+	Streamer   beep.Streamer[S, P]
+	SampleRate beep.SampleRate
+	LFO        LFO
+	Law        PanLaw
+}
+
+// Stream streams the wrapped Streamer auto-panned.
+func (a *AutoPan[S, P]) Stream(samples []P) (n int, ok bool) {
+	n, ok = a.Streamer.Stream(samples)
+	points.Each(samples[:n], func(ch []S) {
+		pan(ch, a.LFO.at(0), a.Law)
+		a.LFO.advance(a.SampleRate)
+	})
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (a *AutoPan[S, P]) Err() error {
+	return a.Streamer.Err()
+}
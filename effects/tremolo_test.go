@@ -0,0 +1,45 @@
+package effects_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+)
+
+func TestTremoloVolume(t *testing.T) {
+	tr := &effects.Tremolo[float64, beep.Stereo[float64]]{
+		Streamer:   constant(8, 1),
+		SampleRate: 8,
+		LFO:        effects.LFO{Shape: effects.Square, Rate: 1, Depth: 1},
+		Base:       2,
+		Volume:     -1,
+	}
+	for i, p := range collectN(tr, -1) {
+		want := 1.0
+		if i >= 4 {
+			want = 0.25
+		}
+		if math.Abs(p[0]-want) > 1e-9 || math.Abs(p[1]-want) > 1e-9 {
+			t.Errorf("sample %d: got %v, want %v", i, p, want)
+		}
+	}
+}
+
+func TestAutoPan(t *testing.T) {
+	a := &effects.AutoPan[float64, beep.Stereo[float64]]{
+		Streamer:   constant(8, 1),
+		SampleRate: 8,
+		LFO:        effects.LFO{Shape: effects.Square, Rate: 1, Depth: 1},
+	}
+	for i, p := range collectN(a, -1) {
+		want := beep.Stereo[float64]{0, 2}
+		if i >= 4 {
+			want = beep.Stereo[float64]{2, 0}
+		}
+		if p != want {
+			t.Errorf("sample %d: got %v, want %v", i, p, want)
+		}
+	}
+}
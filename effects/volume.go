@@ -4,6 +4,7 @@ import (
 	"math"
 
 	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
 )
 
 // Volume adjusts the volume of the wrapped Streamer in a human-natural way. Human's perception of
@@ -33,10 +34,11 @@ func (v *Volume[S, P]) Stream(samples []P) (n int, ok bool) {
 	if !v.Silent {
 		gain = math.Pow(v.Base, v.Volume)
 	}
-	for i := range samples[:n] {
-		samples[i][0] *= S(gain)
-		samples[i][1] *= S(gain)
-	}
+	points.Each(samples[:n], func(ch []S) {
+		for c := range ch {
+			ch[c] *= S(gain)
+		}
+	})
 	return n, ok
 }
 
@@ -0,0 +1,42 @@
+// Package points accesses the channels of beep Points without allocating.
+package points
+
+import (
+	"reflect"
+	"unsafe"
+
+	"github.com/faiface/beep"
+)
+
+// Each calls f with the channel values of every sample, which f may modify in place.
+//
+// Going through Point's Get and Set allocates on every Set for samples bigger than a pointer, so
+// Points which are arrays of their channels, such as Mono, Stereo and the Points of the ambisonics
+// package, are modified directly. Streamers which must not allocate while streaming should use
+// Each.
+func Each[S beep.Size, P beep.Point[S]](samples []P, f func(ch []S)) {
+	var p P
+	n := p.Count()
+	if isArray[S, P](n) {
+		for i := range samples {
+			f(unsafe.Slice((*S)(unsafe.Pointer(&samples[i])), n))
+		}
+		return
+	}
+	ch := make([]S, n)
+	for i := range samples {
+		for c := range ch {
+			ch[c] = samples[i].Get(c)
+		}
+		f(ch)
+		for c, v := range ch {
+			samples[i] = samples[i].Set(c, v).(P)
+		}
+	}
+}
+
+// isArray reports whether P is an array of n values of S, whose memory can be used as a slice.
+func isArray[S beep.Size, P beep.Point[S]](n int) bool {
+	t := reflect.TypeOf((*P)(nil)).Elem()
+	return t.Kind() == reflect.Array && t.Len() == n && t.Elem() == reflect.TypeOf((*S)(nil)).Elem()
+}
@@ -0,0 +1,68 @@
+package points_test
+
+import (
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+)
+
+// quad is an array Point of a package other than beep.
+type quad [4]float32
+
+func (p quad) Count() int                               { return len(p) }
+func (p quad) Get(i int) float32                        { return p[i] }
+func (p quad) Slice() []float32                         { return p[:] }
+func (p quad) Set(i int, v float32) beep.Point[float32] { p[i] = v; return p }
+func (p quad) Add(i int, v float32) beep.Point[float32] { p[i] += v; return p }
+
+// pair is a Point which isn't an array, so it's accessed through Get and Set.
+type pair struct{ a, b float64 }
+
+func (p pair) Count() int { return 2 }
+func (p pair) Get(i int) float64 {
+	if i == 0 {
+		return p.a
+	}
+	return p.b
+}
+func (p pair) Slice() []float64 { return []float64{p.a, p.b} }
+func (p pair) Set(i int, v float64) beep.Point[float64] {
+	if i == 0 {
+		p.a = v
+	} else {
+		p.b = v
+	}
+	return p
+}
+func (p pair) Add(i int, v float64) beep.Point[float64] { return p.Set(i, p.Get(i)+v) }
+
+func TestEach(t *testing.T) {
+	quads := []quad{{1, 2, 3, 4}, {5, 6, 7, 8}}
+	points.Each(quads, func(ch []float32) {
+		ch[0], ch[3] = ch[3], ch[0]
+	})
+	if quads[0] != (quad{4, 2, 3, 1}) || quads[1] != (quad{8, 6, 7, 5}) {
+		t.Errorf("got %v", quads)
+	}
+
+	pairs := []pair{{1, 2}, {3, 4}}
+	points.Each(pairs, func(ch []float64) {
+		ch[0], ch[1] = ch[1], ch[0]
+	})
+	if pairs[0] != (pair{2, 1}) || pairs[1] != (pair{4, 3}) {
+		t.Errorf("got %v", pairs)
+	}
+}
+
+func TestEachAllocs(t *testing.T) {
+	stereo := make([]beep.Stereo[float64], 512)
+	quads := make([]quad, 512)
+	allocs := testing.AllocsPerRun(100, func() {
+		points.Each(stereo, func(ch []float64) { ch[1] = ch[0] })
+		points.Each(quads, func(ch []float32) { ch[1] = ch[0] })
+	})
+	if allocs != 0 {
+		t.Errorf("Each allocates %v times per call", allocs)
+	}
+}
@@ -0,0 +1,133 @@
+package beep
+
+import "fmt"
+
+// Reblock returns a Streamer which streams s processed by process in blocks of exactly size
+// samples, no matter how many samples are requested from its Stream method. This is useful for
+// block-based algorithms, such as FFT processing or partitioned convolution, which can't handle
+// blocks of arbitrary length.
+//
+// The process function modifies the block in place. The block is owned by the Reblocker and must
+// not be retained after process returns.
+//
+// Collecting a whole block before processing delays the output by Latency samples. The output
+// starts with Latency samples of silence. When s is drained, the last partial block is padded with
+// silence, processed and streamed, so that every sample of s makes it to the output.
+//
+// If size is less than 1, Reblock panics.
+//
+// The returned Streamer propagates s's errors through Err.
+func Reblock[S Size, P Point[S]](size int, s Streamer[S, P], process func(block []P)) *Reblocker[S, P] {
+	if size < 1 {
+		panic(fmt.Errorf("reblock: invalid block size: %d", size))
+	}
+	return &Reblocker[S, P]{
+		s:       s,
+		process: process,
+		block:   make([]P, size),
+		out:     make([]P, size),
+		pending: size - 1,
+	}
+}
+
+// Reblocker is a Streamer created by Reblock.
+type Reblocker[S Size, P Point[S]] struct {
+	s       Streamer[S, P]
+	process func(block []P)
+	block   []P // the block being collected
+	fill    int // number of samples in block
+	out     []P // ring buffer of processed samples waiting to be streamed
+	read    int // read position in out
+	pending int // number of samples waiting in out
+	drained bool
+	remains int // number of samples left to stream after s is drained
+	total   int // number of samples streamed from s
+}
+
+// Stream streams the processed samples of the wrapped Streamer.
+func (r *Reblocker[S, P]) Stream(samples []P) (n int, ok bool) {
+	for n < len(samples) {
+		if !r.drained {
+			sn, sok := r.s.Stream(samples[n:])
+			if !sok {
+				r.drain()
+				continue
+			}
+			for i := n; i < n+sn; i++ {
+				samples[i] = r.push(samples[i])
+			}
+			r.total += sn
+			n += sn
+			continue
+		}
+		if r.remains == 0 {
+			break
+		}
+		samples[n] = r.pop()
+		r.remains--
+		n++
+	}
+	return n, n > 0
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (r *Reblocker[S, P]) Err() error {
+	return r.s.Err()
+}
+
+// Size returns the number of samples in each block handed to the process function.
+func (r *Reblocker[S, P]) Size() int {
+	return len(r.block)
+}
+
+// Latency returns the number of samples by which the output lags behind the input, which is one
+// less than the block size.
+func (r *Reblocker[S, P]) Latency() int {
+	return len(r.block) - 1
+}
+
+// push adds one sample to the current block and returns the next processed sample.
+func (r *Reblocker[S, P]) push(p P) P {
+	r.block[r.fill] = p
+	r.fill++
+	if r.fill == len(r.block) {
+		r.flush(len(r.block))
+	}
+	return r.pop()
+}
+
+// flush processes the current block and queues its first n samples for streaming.
+func (r *Reblocker[S, P]) flush(n int) {
+	if r.process != nil {
+		r.process(r.block)
+	}
+	for _, p := range r.block[:n] {
+		r.out[(r.read+r.pending)%len(r.out)] = p
+		r.pending++
+	}
+	r.fill = 0
+}
+
+func (r *Reblocker[S, P]) pop() P {
+	p := r.out[r.read]
+	r.read = (r.read + 1) % len(r.out)
+	r.pending--
+	return p
+}
+
+// drain processes the last partial block once s is drained.
+func (r *Reblocker[S, P]) drain() {
+	r.drained = true
+	if r.total == 0 || r.s.Err() != nil {
+		return
+	}
+	r.remains = r.Latency()
+	if r.fill > 0 {
+		for i := r.fill; i < len(r.block); i++ {
+			var p P
+			r.block[i] = p
+		}
+		// the padding is not streamed
+		r.flush(r.fill)
+	}
+}
@@ -0,0 +1,47 @@
+package beep_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/faiface/beep"
+)
+
+func TestReblock(t *testing.T) {
+	for _, size := range []int{1, 2, 7, 64, 1000} {
+		s, data := randomDataStreamer[float64, beep.Stereo[float64]](rand.Intn(1e4) + 1)
+
+		var blocks int
+		r := beep.Reblock[float64, beep.Stereo[float64]](size, s, func(block []beep.Stereo[float64]) {
+			if len(block) != size {
+				t.Fatalf("got block of %d samples, want %d", len(block), size)
+			}
+			for i := range block {
+				block[i][0], block[i][1] = block[i][1], block[i][0]
+			}
+			blocks++
+		})
+
+		var got []beep.Stereo[float64]
+		for {
+			buf := make([]beep.Stereo[float64], rand.Intn(300)+1)
+			n, ok := r.Stream(buf)
+			if !ok {
+				break
+			}
+			got = append(got, buf[:n]...)
+		}
+
+		want := make([]beep.Stereo[float64], r.Latency(), len(data)+r.Latency())
+		for _, p := range data {
+			want = append(want, beep.Stereo[float64]{p[1], p[0]})
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("size %d: Reblock not working correctly", size)
+		}
+		if wantBlocks := (len(data) + size - 1) / size; blocks != wantBlocks {
+			t.Errorf("size %d: got %d blocks, want %d", size, blocks, wantBlocks)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package beep_test
 
 import (
+	"math"
 	"testing"
 
 	"github.com/faiface/beep"
@@ -115,3 +116,99 @@ func lagrange[S beep.Size](pts []point[S], x S) (y S) {
 type point[S beep.Size] struct {
 	X, Y S
 }
+
+// sineData returns n samples of a sine wave of the frequency freq at the sample rate sr.
+func sineData(sr beep.SampleRate, freq float64, n int) []beep.Mono[float64] {
+	data := make([]beep.Mono[float64], n)
+	for i := range data {
+		data[i][0] = math.Sin(2 * math.Pi * freq * float64(i) / float64(sr))
+	}
+	return data
+}
+
+func TestResampleSinc(t *testing.T) {
+	const old, new = beep.SampleRate(48000), beep.SampleRate(16000)
+	level := func(freq float64) float64 {
+		data := sineData(old, freq, int(old))
+		s := beep.StreamerFunc[float64, beep.Mono[float64]](func(samples []beep.Mono[float64]) (int, bool) {
+			if len(data) == 0 {
+				return 0, false
+			}
+			n := copy(samples, data)
+			data = data[n:]
+			return n, true
+		})
+		got := collect[float64, beep.Mono[float64]](beep.ResampleSinc[float64, beep.Mono[float64]](16, old, new, s))
+		var peak float64
+		for _, p := range got[len(got)/4 : 3*len(got)/4] {
+			peak = math.Max(peak, math.Abs(p[0]))
+		}
+		return peak
+	}
+	// below the new Nyquist frequency passes, above it is removed instead of aliasing
+	if l := level(1000); math.Abs(l-1) > 0.01 {
+		t.Errorf("1000 Hz: got level %v, want 1", l)
+	}
+	if l := level(12000); l > 0.001 {
+		t.Errorf("12000 Hz: got level %v, want 0", l)
+	}
+}
+
+func TestResampleSincConstant(t *testing.T) {
+	for _, rates := range [][2]beep.SampleRate{{44100, 48000}, {48000, 44100}, {100, 800}, {800, 100}} {
+		n := 5000
+		s := beep.StreamerFunc[float64, beep.Mono[float64]](func(samples []beep.Mono[float64]) (int, bool) {
+			if n == 0 {
+				return 0, false
+			}
+			if len(samples) > n {
+				samples = samples[:n]
+			}
+			for i := range samples {
+				samples[i][0] = 0.5
+			}
+			n -= len(samples)
+			return len(samples), true
+		})
+		got := collect[float64, beep.Mono[float64]](beep.ResampleSinc[float64, beep.Mono[float64]](8, rates[0], rates[1], s))
+		// the edges are filtered against the silence around the stream
+		edge := 100 * int(rates[1]) / int(rates[0])
+		for i, p := range got[edge+10 : len(got)-edge-10] {
+			if math.Abs(p[0]-0.5) > 1e-3 {
+				t.Fatalf("%v: sample %d: got %v, want 0.5", rates, i+edge+10, p[0])
+			}
+		}
+	}
+}
+
+func TestResamplerSetRatio(t *testing.T) {
+	// a ramp is interpolated exactly, so each sample must advance it by the current ratio
+	var x float64
+	ramp := beep.StreamerFunc[float64, beep.Mono[float64]](func(samples []beep.Mono[float64]) (int, bool) {
+		for i := range samples {
+			samples[i][0] = x
+			x++
+		}
+		return len(samples), true
+	})
+	r := beep.ResampleRatio[float64, beep.Mono[float64]](3, 1, ramp)
+	buf := make([]beep.Mono[float64], 100)
+	r.Stream(buf)
+	prev, last := buf[len(buf)-1][0], 1.0
+	for _, ratio := range []float64{0.7, 1.3, 1.0 / 3, 2.5, 1} {
+		r.SetRatio(ratio)
+		r.Stream(buf)
+		for i, p := range buf {
+			// the step to the first sample was taken at the previous ratio
+			want := ratio
+			if i == 0 {
+				want = last
+			}
+			if d := p[0] - prev; math.Abs(d-want) > 1e-6 {
+				t.Fatalf("ratio %v: sample %d: advances by %v, want %v", ratio, i, d, want)
+			}
+			prev = p[0]
+		}
+		last = ratio
+	}
+}
@@ -0,0 +1,106 @@
+package spatial
+
+import (
+	"fmt"
+	"math"
+)
+
+// Rolloff is the curve by which the gain of an Emitter falls with its distance from the Listener.
+type Rolloff int
+
+const (
+	// InverseRolloff falls with the inverse of the distance, like sound in free space. Each
+	// doubling of the distance lowers the gain by 6 dB with a Factor of 1.
+	InverseRolloff Rolloff = iota
+	// LinearRolloff falls linearly from 1 at RefDistance to 0 at MaxDistance with a Factor of 1.
+	LinearRolloff
+	// ExponentialRolloff falls with the distance raised to the power of -Factor.
+	ExponentialRolloff
+)
+
+// String returns the name of the rolloff.
+func (r Rolloff) String() string {
+	switch r {
+	case InverseRolloff:
+		return "InverseRolloff"
+	case LinearRolloff:
+		return "LinearRolloff"
+	case ExponentialRolloff:
+		return "ExponentialRolloff"
+	}
+	return fmt.Sprintf("Rolloff(%d)", int(r))
+}
+
+// DistanceModel describes how the gain of an Emitter depends on its distance from the Listener,
+// the same way as the distance models of OpenAL.
+//
+// Closer than RefDistance, the gain is 1. Farther than MaxDistance, the gain stops falling. The
+// zero DistanceModel is an inverse rolloff from 1 meter on, without a maximum distance.
+type DistanceModel struct {
+	Rolloff Rolloff
+	// RefDistance is the distance in meters at which the gain is 1. Zero means 1 meter.
+	RefDistance float64
+	// MaxDistance is the distance in meters beyond which the gain doesn't fall. Zero means no
+	// limit, except for LinearRolloff, which requires it.
+	MaxDistance float64
+	// Factor scales the rolloff. Zero means 1.
+	Factor float64
+}
+
+// Gain returns the gain at the distance d in meters.
+func (m DistanceModel) Gain(d float64) float64 {
+	ref, max, factor := m.RefDistance, m.MaxDistance, m.Factor
+	if ref <= 0 {
+		ref = 1
+	}
+	if max <= 0 {
+		max = math.Inf(1)
+	}
+	if factor == 0 {
+		factor = 1
+	}
+	d = math.Max(ref, math.Min(max, d))
+
+	switch m.Rolloff {
+	case LinearRolloff:
+		if math.IsInf(max, 1) || max <= ref {
+			return 1
+		}
+		return math.Max(0, 1-factor*(d-ref)/(max-ref))
+	case ExponentialRolloff:
+		return math.Pow(d/ref, -factor)
+	default:
+		return ref / (ref + factor*(d-ref))
+	}
+}
+
+// Cone describes the directivity of an Emitter. Inside the inner cone around the direction of the
+// Emitter, the gain is 1. Outside the outer cone, the gain is OuterGain. Between them, the gain
+// changes linearly with the angle.
+//
+// The zero Cone is omnidirectional.
+type Cone struct {
+	// Inner and Outer are the full apex angles of the cones in radians, between 0 and 2π.
+	Inner, Outer float64
+	// OuterGain is the gain outside the outer cone, for example 0.2.
+	OuterGain float64
+}
+
+// Gain returns the gain at the angle in radians between the direction of the Emitter and the
+// direction from the Emitter to the Listener.
+func (c Cone) Gain(angle float64) float64 {
+	if c.Outer <= 0 {
+		return 1
+	}
+	inner, outer := c.Inner/2, math.Max(c.Inner, c.Outer)/2
+	angle = math.Abs(angle)
+	switch {
+	case angle <= inner:
+		return 1
+	case angle >= outer:
+		return c.OuterGain
+	default:
+		t := (angle - inner) / (outer - inner)
+		return 1 + t*(c.OuterGain-1)
+	}
+}
@@ -0,0 +1,55 @@
+package spatial_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/beep/spatial"
+)
+
+func TestDistanceModel(t *testing.T) {
+	tests := []struct {
+		model spatial.DistanceModel
+		d     float64
+		want  float64
+	}{
+		{spatial.DistanceModel{}, 0.5, 1},
+		{spatial.DistanceModel{}, 2, 0.5},
+		{spatial.DistanceModel{}, 8, 0.125},
+		{spatial.DistanceModel{RefDistance: 2, MaxDistance: 4}, 100, 0.5},
+		{spatial.DistanceModel{Factor: 2}, 3, 0.2},
+		{spatial.DistanceModel{Rolloff: spatial.LinearRolloff, RefDistance: 10, MaxDistance: 20}, 15, 0.5},
+		{spatial.DistanceModel{Rolloff: spatial.LinearRolloff, RefDistance: 10, MaxDistance: 20}, 30, 0},
+		{spatial.DistanceModel{Rolloff: spatial.LinearRolloff, MaxDistance: 21, Factor: 0.5}, 21, 0.5},
+		{spatial.DistanceModel{Rolloff: spatial.ExponentialRolloff, Factor: 2}, 4, 1.0 / 16},
+		{spatial.DistanceModel{Rolloff: spatial.ExponentialRolloff, RefDistance: 2}, 8, 0.25},
+	}
+	for _, test := range tests {
+		if got := test.model.Gain(test.d); math.Abs(got-test.want) > 1e-12 {
+			t.Errorf("%+v at %v m: got %v, want %v", test.model, test.d, got, test.want)
+		}
+	}
+}
+
+func TestCone(t *testing.T) {
+	cone := spatial.Cone{Inner: math.Pi / 2, Outer: math.Pi, OuterGain: 0.2}
+	tests := []struct {
+		angle float64
+		want  float64
+	}{
+		{0, 1},
+		{math.Pi / 4, 1},
+		{-math.Pi / 4, 1},
+		{3 * math.Pi / 8, 0.6},
+		{math.Pi / 2, 0.2},
+		{math.Pi, 0.2},
+	}
+	for _, test := range tests {
+		if got := cone.Gain(test.angle); math.Abs(got-test.want) > 1e-12 {
+			t.Errorf("angle %v: got %v, want %v", test.angle, got, test.want)
+		}
+	}
+	if got := (spatial.Cone{}).Gain(math.Pi); got != 1 {
+		t.Errorf("zero cone: got %v, want 1", got)
+	}
+}
@@ -0,0 +1,14 @@
+// Package spatial places sounds in 3D space around a listener.
+//
+// A Listener is the point of view, usually the camera or the player. Each sound is an Emitter,
+// which wraps a Streamer and renders it in stereo as heard by the Listener: attenuated with
+// distance, shaped by its directional cone, pitch-shifted by the Doppler effect of their motion
+// and panned by its direction.
+//
+// A RoomEmitter adds the early reflections and the reverberation of a rectangular Room, and
+// NewBinauralEmitter renders an Emitter for headphones with the HRIRs of an HRIRSet.
+//
+// The coordinate system is right-handed with Y up. Positions are in meters and velocities in
+// meters per second. The position, velocity and orientation of the Listener and the Emitters
+// may be updated from any goroutine, such as the game loop, while they are playing.
+package spatial
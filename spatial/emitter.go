@@ -0,0 +1,204 @@
+package spatial
+
+import (
+	"math"
+	"sync"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+)
+
+// dopplerQuality is the quality of the resampler which shifts the pitch for the Doppler effect.
+const dopplerQuality = 3
+
+// EmitterParams are the parameters of an Emitter.
+type EmitterParams struct {
+	Position Vec3
+	Velocity Vec3
+	// Direction is the axis of the Cone. It doesn't have to be normalized. The zero vector makes
+	// the Emitter omnidirectional.
+	Direction Vec3
+	Distance  DistanceModel
+	Cone      Cone
+	// DopplerFactor scales the Doppler effect. 1 is realistic, 0 disables it.
+	DopplerFactor float64
+}
+
+// Emitter is a sound source in 3D space, heard by a Listener. It streams the wrapped Streamer mixed
+// down to mono, pitch-shifted by the Doppler effect, attenuated by the DistanceModel and the Cone
+// and panned by its azimuth with an equal-power pan law into the first two channels.
+//
+// The parameters are read once for each call to Stream, and the gains change smoothly over the
+// streamed samples, so moving the Emitter or the Listener doesn't click. All the methods other
+// than Stream and Err are safe to call from multiple goroutines.
+//
+// An Emitter created by NewBinauralEmitter is rendered with HRIRs instead of panned.
+type Emitter[S beep.Size, P beep.Point[S]] struct {
+	listener *Listener
+	r        *beep.Resampler[S, P]
+	hrtf     *hrtfRenderer // nil unless binaural
+
+	mu     sync.Mutex
+	params EmitterParams
+
+	gains   [2]float64 // gains of the left and the right channel at the end of the last Stream
+	started bool
+}
+
+// NewEmitter returns an Emitter of s heard by the listener. It starts at the origin, at rest,
+// omnidirectional, with the zero DistanceModel and the DopplerFactor of 1.
+//
+// The returned Emitter propagates s's errors through Err.
+func NewEmitter[S beep.Size, P beep.Point[S]](s beep.Streamer[S, P], listener *Listener) *Emitter[S, P] {
+	return &Emitter[S, P]{
+		listener: listener,
+		r:        beep.ResampleRatio(dopplerQuality, 1, s),
+		params:   EmitterParams{DopplerFactor: 1},
+	}
+}
+
+// NewBinauralEmitter returns an Emitter of s with the sample rate sr, like NewEmitter, which is
+// rendered binaurally for headphones with the HRIRs of set instead of panned, so it can be heard
+// from above, below and behind. If the sample rate of set differs from sr, the HRIRs are
+// resampled. NewBinauralEmitter returns an error if set is empty or its HRIRs have different
+// lengths.
+//
+// The returned Emitter propagates s's errors through Err.
+func NewBinauralEmitter[S beep.Size, P beep.Point[S]](s beep.Streamer[S, P], sr beep.SampleRate, listener *Listener, set *HRIRSet) (*Emitter[S, P], error) {
+	h, err := newHRTFRenderer(set, sr)
+	if err != nil {
+		return nil, err
+	}
+	e := NewEmitter(s, listener)
+	e.hrtf = h
+	return e, nil
+}
+
+// Params returns the current parameters of the Emitter.
+func (e *Emitter[S, P]) Params() EmitterParams {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.params
+}
+
+// SetParams sets all the parameters of the Emitter at once.
+func (e *Emitter[S, P]) SetParams(p EmitterParams) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.params = p
+}
+
+// SetPosition moves the Emitter to pos.
+func (e *Emitter[S, P]) SetPosition(pos Vec3) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.params.Position = pos
+}
+
+// SetVelocity sets the velocity of the Emitter, which is used for the Doppler effect.
+func (e *Emitter[S, P]) SetVelocity(vel Vec3) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.params.Velocity = vel
+}
+
+// SetDirection points the Cone of the Emitter in the direction dir.
+func (e *Emitter[S, P]) SetDirection(dir Vec3) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.params.Direction = dir
+}
+
+// Stream streams the wrapped Streamer as heard by the Listener.
+func (e *Emitter[S, P]) Stream(samples []P) (n int, ok bool) {
+	lp, ep := e.listener.Params(), e.Params()
+
+	e.r.SetRatio(dopplerRatio(lp, ep))
+	n, ok = e.r.Stream(samples)
+
+	gain := emitterGain(lp, ep)
+	azimuth, elevation, _ := lp.Direction(ep.Position)
+	t := (math.Sin(azimuth) + 1) / 2
+	target := [2]float64{gain * math.Cos(t*math.Pi/2), gain * math.Sin(t*math.Pi/2)}
+	if e.hrtf != nil {
+		// the HRIRs do the panning
+		target = [2]float64{gain, gain}
+	}
+	if !e.started {
+		e.gains, e.started = target, true
+	}
+
+	i := 0
+	points.Each(samples[:n], func(ch []S) {
+		// ramp the gains linearly over the streamed samples
+		i++
+		f := float64(i) / float64(n)
+		l := e.gains[0] + f*(target[0]-e.gains[0])
+		r := e.gains[1] + f*(target[1]-e.gains[1])
+
+		var mono float64
+		for _, x := range ch {
+			mono += float64(x)
+		}
+		mono /= float64(len(ch))
+
+		if e.hrtf != nil {
+			for c := range ch {
+				ch[c] = S(mono * l)
+			}
+			return
+		}
+		if len(ch) == 1 {
+			ch[0] = S(mono * math.Hypot(l, r))
+			return
+		}
+		ch[0], ch[1] = S(mono*l), S(mono*r)
+		for c := 2; c < len(ch); c++ {
+			ch[c] = 0
+		}
+	})
+	if n > 0 {
+		e.gains = target
+	}
+	if e.hrtf != nil {
+		renderHRTF(e.hrtf, samples[:n], azimuth, elevation)
+	}
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (e *Emitter[S, P]) Err() error {
+	return e.r.Err()
+}
+
+// emitterGain returns the gain of the Emitter due to its distance and its cone.
+func emitterGain(lp ListenerParams, ep EmitterParams) float64 {
+	rel := lp.Position.Sub(ep.Position)
+	dist := rel.Len()
+	gain := ep.Distance.Gain(dist)
+	if ep.Direction != (Vec3{}) && dist > 0 {
+		cos := ep.Direction.Unit().Dot(rel.Scale(1 / dist))
+		gain *= ep.Cone.Gain(math.Acos(math.Max(-1, math.Min(1, cos))))
+	}
+	return gain
+}
+
+// dopplerRatio returns the ratio by which the motion of the Listener and the Emitter shifts the
+// frequencies of the Emitter.
+func dopplerRatio(lp ListenerParams, ep EmitterParams) float64 {
+	rel := ep.Position.Sub(lp.Position)
+	dist := rel.Len()
+	if ep.DopplerFactor == 0 || dist == 0 {
+		return 1
+	}
+	c := lp.SpeedOfSound
+	if c <= 0 {
+		c = DefaultSpeedOfSound
+	}
+	dir := rel.Scale(1 / dist)
+	// the speeds towards each other, limited below the speed of sound
+	limit := 0.9 * c
+	vl := math.Max(-limit, math.Min(limit, ep.DopplerFactor*lp.Velocity.Dot(dir)))
+	ve := math.Max(-limit, math.Min(limit, -ep.DopplerFactor*ep.Velocity.Dot(dir)))
+	return (c + vl) / (c - ve)
+}
@@ -0,0 +1,154 @@
+package spatial_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/spatial"
+)
+
+// ones streams a constant of 1 forever and counts the streamed samples.
+type ones struct {
+	count int
+}
+
+func (o *ones) Stream(samples []beep.Stereo[float64]) (n int, ok bool) {
+	for i := range samples {
+		samples[i] = beep.Stereo[float64]{1, 1}
+	}
+	o.count += len(samples)
+	return len(samples), true
+}
+
+func (o *ones) Err() error { return nil }
+
+func TestEmitterGainAndPanning(t *testing.T) {
+	l := spatial.NewListener()
+	tests := []struct {
+		pos         spatial.Vec3
+		left, right float64
+	}{
+		{spatial.Vec3{Z: -1}, math.Sqrt2 / 2, math.Sqrt2 / 2},
+		{spatial.Vec3{Z: -4}, math.Sqrt2 / 8, math.Sqrt2 / 8},
+		{spatial.Vec3{X: 2}, 0, 0.5},
+		{spatial.Vec3{X: -1}, 1, 0},
+		{spatial.Vec3{Z: 1}, math.Sqrt2 / 2, math.Sqrt2 / 2},
+	}
+	for _, test := range tests {
+		e := spatial.NewEmitter[float64, beep.Stereo[float64]](&ones{}, l)
+		e.SetPosition(test.pos)
+		buf := make([]beep.Stereo[float64], 100)
+		e.Stream(buf)
+		got := buf[99]
+		if math.Abs(got[0]-test.left) > 1e-9 || math.Abs(got[1]-test.right) > 1e-9 {
+			t.Errorf("%+v: got %v, want [%v %v]", test.pos, got, test.left, test.right)
+		}
+	}
+}
+
+func TestEmitterSmoothsMovement(t *testing.T) {
+	e := spatial.NewEmitter[float64, beep.Stereo[float64]](&ones{}, spatial.NewListener())
+	e.SetPosition(spatial.Vec3{X: -1})
+	buf := make([]beep.Stereo[float64], 100)
+	e.Stream(buf)
+	e.SetPosition(spatial.Vec3{X: 1})
+	e.Stream(buf)
+	for i := 1; i < len(buf); i++ {
+		if step := math.Abs(buf[i][0] - buf[i-1][0]); step > 0.02 {
+			t.Fatalf("sample %d: jumps by %v", i, step)
+		}
+	}
+	if buf[99][0] > 1e-9 || math.Abs(buf[99][1]-1) > 1e-9 {
+		t.Errorf("got %v at the end, want [0 1]", buf[99])
+	}
+}
+
+func TestEmitterCone(t *testing.T) {
+	e := spatial.NewEmitter[float64, beep.Stereo[float64]](&ones{}, spatial.NewListener())
+	p := e.Params()
+	p.Position = spatial.Vec3{Z: -1}
+	p.Cone = spatial.Cone{Inner: math.Pi / 4, Outer: math.Pi / 2, OuterGain: 0.25}
+	buf := make([]beep.Stereo[float64], 10)
+	for _, test := range []struct {
+		dir  spatial.Vec3
+		want float64
+	}{
+		{spatial.Vec3{Z: 1}, 1},     // facing the listener
+		{spatial.Vec3{Z: -1}, 0.25}, // facing away
+		{spatial.Vec3{}, 1},         // omnidirectional
+	} {
+		p.Direction = test.dir
+		e.SetParams(p)
+		e.Stream(buf)
+		e.Stream(buf)
+		if got := buf[9][0] / (math.Sqrt2 / 2); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("direction %+v: got gain %v, want %v", test.dir, got, test.want)
+		}
+	}
+}
+
+func TestEmitterDoppler(t *testing.T) {
+	const c = spatial.DefaultSpeedOfSound
+	tests := []struct {
+		emitterVel, listenerVel spatial.Vec3
+		want                    float64
+	}{
+		{spatial.Vec3{}, spatial.Vec3{}, 1},
+		{spatial.Vec3{Z: 0.5 * c}, spatial.Vec3{}, 2},             // approaching
+		{spatial.Vec3{Z: -0.5 * c}, spatial.Vec3{}, 1 / 1.5},      // receding
+		{spatial.Vec3{}, spatial.Vec3{Z: -0.5 * c}, 1.5},          // listener approaching
+		{spatial.Vec3{X: 100}, spatial.Vec3{X: 50}, 1},            // moving across
+		{spatial.Vec3{Z: 0.25 * c}, spatial.Vec3{Z: 0.25 * c}, 1}, // moving together
+	}
+	for _, test := range tests {
+		src := &ones{}
+		l := spatial.NewListener()
+		l.SetVelocity(test.listenerVel)
+		e := spatial.NewEmitter[float64, beep.Stereo[float64]](src, l)
+		e.SetPosition(spatial.Vec3{Z: -10})
+		e.SetVelocity(test.emitterVel)
+
+		buf := make([]beep.Stereo[float64], 1000)
+		e.Stream(buf)
+		start := src.count
+		for i := 0; i < 100; i++ {
+			e.Stream(buf)
+		}
+		if got := float64(src.count-start) / 100000; math.Abs(got-test.want) > 0.01 {
+			t.Errorf("emitter %+v, listener %+v: got ratio %v, want %v", test.emitterVel, test.listenerVel, got, test.want)
+		}
+	}
+}
+
+func TestEmitterDopplerContinuity(t *testing.T) {
+	// a ramp is interpolated exactly, so each output sample must advance it by the current ratio
+	const step = 0.001
+	var x float64
+	ramp := beep.StreamerFunc[float64, beep.Stereo[float64]](func(samples []beep.Stereo[float64]) (int, bool) {
+		for i := range samples {
+			samples[i] = beep.Stereo[float64]{x, x}
+			x += step
+		}
+		return len(samples), true
+	})
+	const c = spatial.DefaultSpeedOfSound
+	e := spatial.NewEmitter[float64, beep.Stereo[float64]](ramp, spatial.NewListener())
+	e.SetPosition(spatial.Vec3{Z: -1})
+
+	buf := make([]beep.Stereo[float64], 100)
+	e.Stream(buf)
+	prev := buf[len(buf)-1][0]
+	for i := 0; i < 50; i++ {
+		// the emitter moves back and forth and changes the ratio between 1/1.1 and 1/0.9
+		e.SetVelocity(spatial.Vec3{Z: 0.1 * c * math.Sin(float64(i))})
+		e.Stream(buf)
+		for j, p := range buf {
+			d := (p[0] - prev) / step / (math.Sqrt2 / 2)
+			if d < 1/1.1-1e-6 || d > 1/0.9+1e-6 {
+				t.Fatalf("Stream %d, sample %d: advances by %v samples", i, j, d)
+			}
+			prev = p[0]
+		}
+	}
+}
@@ -0,0 +1,438 @@
+package spatial
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+)
+
+// HRIR is a head-related impulse response, the filter which the head and the ears apply to a sound
+// coming from one direction, measured at the entrance of each ear.
+type HRIR struct {
+	// Azimuth and Elevation are the direction of the sound in radians, as returned by
+	// ListenerParams.Direction.
+	Azimuth, Elevation float64
+	// Left and Right are the impulse responses of the left and the right ear.
+	Left, Right []float64
+}
+
+// HRIRSet is a set of HRIRs measured from many directions, which together describe how a head
+// hears sounds from anywhere around it. All the HRIRs have the same length.
+type HRIRSet struct {
+	SampleRate beep.SampleRate
+	HRIRs      []HRIR
+}
+
+// hrirNeighbors is the number of nearest measured directions an HRIR is interpolated from.
+const hrirNeighbors = 3
+
+// ReadHRIRSet reads an HRIRSet from r in a simple text format. Empty lines and lines starting with
+// # are ignored. The first line is the header with the sample rate in Hertz and the length of the
+// impulse responses in samples:
+//
+//	hrir 44100 128
+//
+// Each following line is one HRIR: the azimuth and the elevation in degrees, followed by the
+// samples of the left and then the right impulse response:
+//
+//	-30 0 0.0012 0.0561 ... 0.0003 -0.0104 ...
+//
+// WriteHRIRSet writes this format.
+func ReadHRIRSet(r io.Reader) (*HRIRSet, error) {
+	var set *HRIRSet
+	length := 0
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<24)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+
+		if set == nil {
+			if len(fields) != 3 || fields[0] != "hrir" {
+				return nil, fmt.Errorf("spatial: hrir: line %d: expected header \"hrir <sample rate> <length>\"", line)
+			}
+			sr, err1 := strconv.Atoi(fields[1])
+			l, err2 := strconv.Atoi(fields[2])
+			if err1 != nil || err2 != nil || sr <= 0 || l <= 0 {
+				return nil, fmt.Errorf("spatial: hrir: line %d: invalid header", line)
+			}
+			set, length = &HRIRSet{SampleRate: beep.SampleRate(sr)}, l
+			continue
+		}
+
+		if len(fields) != 2+2*length {
+			return nil, fmt.Errorf("spatial: hrir: line %d: got %d values, want %d", line, len(fields), 2+2*length)
+		}
+		values := make([]float64, len(fields))
+		for i, f := range fields {
+			v, err := strconv.ParseFloat(f, 64)
+			if err != nil {
+				return nil, fmt.Errorf("spatial: hrir: line %d: %w", line, err)
+			}
+			values[i] = v
+		}
+		set.HRIRs = append(set.HRIRs, HRIR{
+			Azimuth:   values[0] * math.Pi / 180,
+			Elevation: values[1] * math.Pi / 180,
+			Left:      values[2 : 2+length],
+			Right:     values[2+length:],
+		})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("spatial: hrir: %w", err)
+	}
+	if set == nil {
+		return nil, errors.New("spatial: hrir: no impulse responses")
+	}
+	if err := set.validate(); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// validate returns an error if the set is empty or its HRIRs have different lengths.
+func (set *HRIRSet) validate() error {
+	if len(set.HRIRs) == 0 || len(set.HRIRs[0].Left) == 0 {
+		return errors.New("spatial: hrir: no impulse responses")
+	}
+	length := len(set.HRIRs[0].Left)
+	for _, h := range set.HRIRs {
+		if len(h.Left) != length || len(h.Right) != length {
+			return errors.New("spatial: hrir: impulse responses of different lengths")
+		}
+	}
+	return nil
+}
+
+// WriteHRIRSet writes set to w in the format read by ReadHRIRSet.
+func WriteHRIRSet(w io.Writer, set *HRIRSet) error {
+	bw := bufio.NewWriter(w)
+	length := 0
+	if len(set.HRIRs) > 0 {
+		length = len(set.HRIRs[0].Left)
+	}
+	fmt.Fprintf(bw, "hrir %d %d\n", set.SampleRate, length)
+	for _, h := range set.HRIRs {
+		if len(h.Left) != length || len(h.Right) != length {
+			return errors.New("spatial: hrir: impulse responses of different lengths")
+		}
+		bw.WriteString(strconv.FormatFloat(h.Azimuth*180/math.Pi, 'g', -1, 64))
+		bw.WriteByte(' ')
+		bw.WriteString(strconv.FormatFloat(h.Elevation*180/math.Pi, 'g', -1, 64))
+		for _, x := range append(h.Left[:len(h.Left):len(h.Left)], h.Right...) {
+			bw.WriteByte(' ')
+			bw.WriteString(strconv.FormatFloat(x, 'g', -1, 64))
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// ReadHRIR reads an HRIR from the direction given by azimuth and elevation in radians from s until
+// it's drained. The first channel of s is the left ear, the second channel the right ear. This way,
+// a set can be loaded from a directory of WAV files:
+//
+//	for _, name := range names {
+//		f, _ := os.Open(name)
+//		s, format, err := wav.Decode[float64, beep.Stereo[float64]](f)
+//		// ...
+//		h, err := spatial.ReadHRIR(s, azimuth(name), elevation(name))
+//		// ...
+//		set.SampleRate = format.SampleRate
+//		set.HRIRs = append(set.HRIRs, h)
+//	}
+func ReadHRIR[S beep.Size, P beep.Point[S]](s beep.Streamer[S, P], azimuth, elevation float64) (HRIR, error) {
+	h := HRIR{Azimuth: azimuth, Elevation: elevation}
+	buf := make([]P, 512)
+	for {
+		n, ok := s.Stream(buf)
+		if !ok {
+			break
+		}
+		points.Each(buf[:n], func(ch []S) {
+			h.Left = append(h.Left, float64(ch[0]))
+			h.Right = append(h.Right, float64(ch[len(ch)-1]))
+		})
+	}
+	if err := s.Err(); err != nil {
+		return HRIR{}, fmt.Errorf("spatial: hrir: %w", err)
+	}
+	return h, nil
+}
+
+// resample returns the set resampled to sr.
+func (set *HRIRSet) resample(sr beep.SampleRate) *HRIRSet {
+	if set.SampleRate == sr || set.SampleRate <= 0 {
+		return set
+	}
+	// keep the level of the responses, which would otherwise change with the number of samples
+	scale := float64(set.SampleRate) / float64(sr)
+	resample := func(x []float64) []float64 {
+		data := make([]beep.Mono[float64], len(x))
+		for i := range x {
+			data[i][0] = x[i]
+		}
+		pos := 0
+		s := beep.StreamerFunc[float64, beep.Mono[float64]](func(samples []beep.Mono[float64]) (int, bool) {
+			if pos >= len(data) {
+				return 0, false
+			}
+			n := copy(samples, data[pos:])
+			pos += n
+			return n, true
+		})
+		r := beep.ResampleSinc[float64, beep.Mono[float64]](16, set.SampleRate, sr, s)
+		var y []float64
+		buf := make([]beep.Mono[float64], 512)
+		for {
+			n, ok := r.Stream(buf)
+			if !ok {
+				break
+			}
+			for _, p := range buf[:n] {
+				y = append(y, p[0]*scale)
+			}
+		}
+		return y
+	}
+
+	out := &HRIRSet{SampleRate: sr, HRIRs: make([]HRIR, len(set.HRIRs))}
+	for i, h := range set.HRIRs {
+		out.HRIRs[i] = HRIR{h.Azimuth, h.Elevation, resample(h.Left), resample(h.Right)}
+	}
+	// the resampler may round the lengths differently
+	length := math.MaxInt
+	for _, h := range out.HRIRs {
+		if len(h.Left) < length {
+			length = len(h.Left)
+		}
+		if len(h.Right) < length {
+			length = len(h.Right)
+		}
+	}
+	for i := range out.HRIRs {
+		out.HRIRs[i].Left = out.HRIRs[i].Left[:length]
+		out.HRIRs[i].Right = out.HRIRs[i].Right[:length]
+	}
+	return out
+}
+
+// Lookup returns the impulse responses of the left and the right ear for the direction given by
+// azimuth and elevation in radians. Unless the direction was measured, the responses are
+// interpolated from the nearest measured directions, weighted by the inverse square of their
+// angular distance.
+func (set *HRIRSet) Lookup(azimuth, elevation float64) (left, right []float64) {
+	length := len(set.HRIRs[0].Left)
+	left, right = make([]float64, length), make([]float64, length)
+	set.lookup(left, right, azimuth, elevation)
+	return left, right
+}
+
+// lookup writes the interpolated impulse responses for the direction into left and right without
+// allocating, so it can be called while streaming.
+func (set *HRIRSet) lookup(left, right []float64, azimuth, elevation float64) {
+	type neighbor struct {
+		i     int
+		angle float64
+	}
+	// keep the nearest directions sorted by inserting each HRIR into them
+	var nearest [hrirNeighbors]neighbor
+	count := 0
+	dir := sphericalToVec(azimuth, elevation)
+	for i, h := range set.HRIRs {
+		cos := dir.Dot(sphericalToVec(h.Azimuth, h.Elevation))
+		n := neighbor{i, math.Acos(math.Max(-1, math.Min(1, cos)))}
+		if count == len(nearest) && n.angle >= nearest[count-1].angle {
+			continue
+		}
+		if count < len(nearest) {
+			count++
+		}
+		k := count - 1
+		for ; k > 0 && nearest[k-1].angle > n.angle; k-- {
+			nearest[k] = nearest[k-1]
+		}
+		nearest[k] = n
+	}
+	neighbors := nearest[:count]
+
+	for i := range left {
+		left[i], right[i] = 0, 0
+	}
+	if neighbors[0].angle < 1e-9 {
+		copy(left, set.HRIRs[neighbors[0].i].Left)
+		copy(right, set.HRIRs[neighbors[0].i].Right)
+		return
+	}
+	var sum float64
+	for _, n := range neighbors {
+		sum += 1 / (n.angle * n.angle)
+	}
+	for _, n := range neighbors {
+		w := 1 / (n.angle * n.angle) / sum
+		h := set.HRIRs[n.i]
+		for k := range left {
+			left[k] += w * h.Left[k]
+			right[k] += w * h.Right[k]
+		}
+	}
+}
+
+// sphericalToVec returns the unit vector of the direction given by azimuth and elevation, with X
+// to the right, Y up and Z to the front.
+func sphericalToVec(azimuth, elevation float64) Vec3 {
+	return Vec3{
+		X: math.Cos(elevation) * math.Sin(azimuth),
+		Y: math.Sin(elevation),
+		Z: math.Cos(elevation) * math.Cos(azimuth),
+	}
+}
+
+// Binaural renders the wrapped Streamer, mixed down to mono, in the first two channels as heard
+// from a direction on headphones, by convolving it with the HRIRs of that direction.
+//
+// When the direction changes, the old and the new HRIRs are crossfaded over the next call to
+// Stream, so moving sources don't click. SetDirection is safe to call from multiple goroutines.
+type Binaural[S beep.Size, P beep.Point[S]] struct {
+	s beep.Streamer[S, P]
+	h *hrtfRenderer
+
+	mu                 sync.Mutex
+	azimuth, elevation float64
+}
+
+// NewBinaural returns a Binaural of s with the sample rate sr, rendered with the HRIRs of set. If
+// the sample rate of set differs from sr, the HRIRs are resampled. The Binaural starts in front of
+// the listener. NewBinaural returns an error if set is empty or its HRIRs have different lengths.
+//
+// The returned Binaural propagates s's errors through Err.
+func NewBinaural[S beep.Size, P beep.Point[S]](s beep.Streamer[S, P], sr beep.SampleRate, set *HRIRSet) (*Binaural[S, P], error) {
+	h, err := newHRTFRenderer(set, sr)
+	if err != nil {
+		return nil, err
+	}
+	return &Binaural[S, P]{s: s, h: h}, nil
+}
+
+// SetDirection sets the direction of the sound, as returned by ListenerParams.Direction.
+func (b *Binaural[S, P]) SetDirection(azimuth, elevation float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.azimuth, b.elevation = azimuth, elevation
+}
+
+// Stream streams the wrapped Streamer rendered binaurally.
+func (b *Binaural[S, P]) Stream(samples []P) (n int, ok bool) {
+	b.mu.Lock()
+	azimuth, elevation := b.azimuth, b.elevation
+	b.mu.Unlock()
+
+	n, ok = b.s.Stream(samples)
+	renderHRTF(b.h, samples[:n], azimuth, elevation)
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (b *Binaural[S, P]) Err() error {
+	return b.s.Err()
+}
+
+// hrtfRenderer holds the state of the binaural rendering of a mono signal.
+type hrtfRenderer struct {
+	set     *HRIRSet
+	history []float64 // the last len(set) input samples, written twice to avoid wrapping around
+	pos     int
+	cur     [2][]float64 // impulse responses of the left and the right ear in use
+	next    [2][]float64 // impulse responses to crossfade to
+	az, el  float64      // direction of cur
+	started bool
+}
+
+func newHRTFRenderer(set *HRIRSet, sr beep.SampleRate) (*hrtfRenderer, error) {
+	if err := set.validate(); err != nil {
+		return nil, err
+	}
+	set = set.resample(sr)
+	length := len(set.HRIRs[0].Left)
+	h := &hrtfRenderer{set: set, history: make([]float64, 2*length)}
+	for i := range h.cur {
+		h.cur[i] = make([]float64, length)
+		h.next[i] = make([]float64, length)
+	}
+	return h, nil
+}
+
+// renderHRTF replaces the first two channels of samples by their mono mix convolved with the
+// HRIRs of the direction, crossfading from the HRIRs of the previous direction if it changed.
+func renderHRTF[S beep.Size, P beep.Point[S]](h *hrtfRenderer, samples []P, azimuth, elevation float64) {
+	if !h.started || azimuth != h.az || elevation != h.el {
+		h.set.lookup(h.next[0], h.next[1], azimuth, elevation)
+		if !h.started {
+			copy(h.cur[0], h.next[0])
+			copy(h.cur[1], h.next[1])
+			h.started = true
+		}
+	}
+	fade := azimuth != h.az || elevation != h.el
+	length := len(h.cur[0])
+
+	i := 0
+	points.Each(samples, func(ch []S) {
+		var mono float64
+		for _, x := range ch {
+			mono += float64(x)
+		}
+		mono /= float64(len(ch))
+
+		h.history[h.pos] = mono
+		h.history[h.pos+length] = mono
+		recent := h.history[h.pos+1 : h.pos+1+length] // oldest first
+		h.pos = (h.pos + 1) % length
+
+		var out [2]float64
+		for e := range out {
+			out[e] = convolveLast(h.cur[e], recent)
+			if fade {
+				t := float64(i+1) / float64(len(samples))
+				out[e] += t * (convolveLast(h.next[e], recent) - out[e])
+			}
+		}
+		i++
+
+		if len(ch) == 1 {
+			ch[0] = S((out[0] + out[1]) / 2)
+			return
+		}
+		ch[0], ch[1] = S(out[0]), S(out[1])
+		for c := 2; c < len(ch); c++ {
+			ch[c] = 0
+		}
+	})
+
+	if fade && len(samples) > 0 {
+		h.cur, h.next = h.next, h.cur
+		h.az, h.el = azimuth, elevation
+	}
+}
+
+// convolveLast returns the last sample of the convolution of the impulse response ir with the
+// signal x, which is as long as ir, oldest sample first.
+func convolveLast(ir, x []float64) float64 {
+	var y float64
+	last := len(x) - 1
+	for k, c := range ir {
+		y += c * x[last-k]
+	}
+	return y
+}
@@ -0,0 +1,242 @@
+package spatial_test
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/spatial"
+)
+
+// impulseSet returns a set which delays the ear away from the sound by a sample for every 30
+// degrees of azimuth, measured every 30 degrees.
+func impulseSet() *spatial.HRIRSet {
+	set := &spatial.HRIRSet{SampleRate: 44100}
+	for deg := -180; deg < 180; deg += 30 {
+		az := float64(deg) * math.Pi / 180
+		h := spatial.HRIR{Azimuth: az, Left: make([]float64, 8), Right: make([]float64, 8)}
+		delay := int(math.Abs(float64(deg)) / 30)
+		if az > 0 {
+			h.Left[delay], h.Right[0] = 1, 1
+		} else {
+			h.Left[0], h.Right[delay] = 1, 1
+		}
+		set.HRIRs = append(set.HRIRs, h)
+	}
+	return set
+}
+
+// impulse streams a single impulse followed by silence.
+func impulse(n int) beep.Streamer[float64, beep.Stereo[float64]] {
+	pos := 0
+	return beep.StreamerFunc[float64, beep.Stereo[float64]](func(samples []beep.Stereo[float64]) (int, bool) {
+		if pos >= n {
+			return 0, false
+		}
+		for i := range samples {
+			samples[i] = beep.Stereo[float64]{}
+			if pos+i == 0 {
+				samples[i] = beep.Stereo[float64]{1, 1}
+			}
+		}
+		pos += len(samples)
+		return len(samples), true
+	})
+}
+
+func TestHRIRSetReadWrite(t *testing.T) {
+	set := impulseSet()
+	var buf bytes.Buffer
+	if err := spatial.WriteHRIRSet(&buf, set); err != nil {
+		t.Fatal(err)
+	}
+	got, err := spatial.ReadHRIRSet(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.SampleRate != set.SampleRate || len(got.HRIRs) != len(set.HRIRs) {
+		t.Fatalf("got %v with %d HRIRs, want %v with %d", got.SampleRate, len(got.HRIRs), set.SampleRate, len(set.HRIRs))
+	}
+	for i, h := range got.HRIRs {
+		want := set.HRIRs[i]
+		if math.Abs(h.Azimuth-want.Azimuth) > 1e-12 || h.Elevation != want.Elevation {
+			t.Errorf("HRIR %d: got direction %v %v, want %v %v", i, h.Azimuth, h.Elevation, want.Azimuth, want.Elevation)
+		}
+		for k := range h.Left {
+			if h.Left[k] != want.Left[k] || h.Right[k] != want.Right[k] {
+				t.Errorf("HRIR %d: got %v %v, want %v %v", i, h.Left, h.Right, want.Left, want.Right)
+				break
+			}
+		}
+	}
+}
+
+func TestReadHRIRSetErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"# only a comment\n",
+		"hrir 44100\n",
+		"hrir 44100 2\n",
+		"hrir 44100 2\n0 0 1 0 1\n",
+		"hrir 44100 2\n0 0 1 0 x 0\n",
+	}
+	for _, test := range tests {
+		if _, err := spatial.ReadHRIRSet(strings.NewReader(test)); err == nil {
+			t.Errorf("%q: expected an error", test)
+		}
+	}
+}
+
+func TestReadHRIR(t *testing.T) {
+	data := []beep.Stereo[float64]{{1, 0.5}, {0, 0.25}, {-1, 0}}
+	pos := 0
+	s := beep.StreamerFunc[float64, beep.Stereo[float64]](func(samples []beep.Stereo[float64]) (int, bool) {
+		if pos >= len(data) {
+			return 0, false
+		}
+		n := copy(samples, data[pos:])
+		pos += n
+		return n, true
+	})
+	h, err := spatial.ReadHRIR[float64, beep.Stereo[float64]](s, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Azimuth != 1 || h.Elevation != 2 || len(h.Left) != 3 || h.Left[2] != -1 || h.Right[1] != 0.25 {
+		t.Errorf("got %+v", h)
+	}
+}
+
+func TestHRIRSetLookup(t *testing.T) {
+	set := impulseSet()
+
+	// measured directions are returned as they are
+	left, right := set.Lookup(math.Pi/2, 0)
+	if left[3] != 1 || right[0] != 1 {
+		t.Errorf("90 degrees: got %v %v", left, right)
+	}
+
+	// halfway between two measurements, both weigh the same and the most
+	left, _ = set.Lookup(75*math.Pi/180, 0)
+	if math.Abs(left[2]-left[3]) > 1e-12 || left[2] < 0.4 || math.Abs(sum(left)-1) > 1e-12 {
+		t.Errorf("75 degrees: got %v", left)
+	}
+}
+
+func sum(x []float64) float64 {
+	var s float64
+	for _, v := range x {
+		s += v
+	}
+	return s
+}
+
+func TestBinaural(t *testing.T) {
+	b, err := spatial.NewBinaural[float64, beep.Stereo[float64]](impulse(16), 44100, impulseSet())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.SetDirection(math.Pi/2, 0)
+	samples := make([]beep.Stereo[float64], 16)
+	b.Stream(samples)
+
+	// the first call to Stream starts at the direction without crossfading
+	for i, p := range samples {
+		want := beep.Stereo[float64]{}
+		if i == 0 {
+			want[1] = 1
+		}
+		if i == 3 {
+			want[0] = 1
+		}
+		if p != want {
+			t.Errorf("sample %d: got %v, want %v", i, p, want)
+		}
+	}
+}
+
+func TestBinauralCrossfade(t *testing.T) {
+	b, err := spatial.NewBinaural[float64, beep.Stereo[float64]](&ones{}, 44100, impulseSet())
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples := make([]beep.Stereo[float64], 100)
+	b.Stream(samples)
+	if samples[99] != (beep.Stereo[float64]{1, 1}) {
+		t.Fatalf("got %v, want [1 1]", samples[99])
+	}
+
+	// a constant is the same from any direction, so the output must stay constant while fading
+	b.SetDirection(-math.Pi/2, 0)
+	b.Stream(samples)
+	for i, p := range samples {
+		if math.Abs(p[0]-1) > 1e-12 || math.Abs(p[1]-1) > 1e-12 {
+			t.Fatalf("sample %d: got %v, want [1 1]", i, p)
+		}
+	}
+}
+
+func TestBinauralEmitter(t *testing.T) {
+	l := spatial.NewListener()
+	e, err := spatial.NewBinauralEmitter[float64, beep.Stereo[float64]](impulse(16), 44100, l, impulseSet())
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.SetParams(spatial.EmitterParams{
+		Position: spatial.Vec3{X: -1},
+		Distance: spatial.DistanceModel{Rolloff: spatial.InverseRolloff, RefDistance: 0.5},
+	})
+	samples := make([]beep.Stereo[float64], 16)
+	e.Stream(samples)
+	if math.Abs(samples[0][0]-0.5) > 1e-3 || math.Abs(samples[3][1]-0.5) > 1e-3 {
+		t.Errorf("got %v, want 0.5 in the left channel at 0 and in the right at 3", samples[:4])
+	}
+}
+
+func TestHRIRSetResample(t *testing.T) {
+	set := impulseSet()
+	b, err := spatial.NewBinaural[float64, beep.Stereo[float64]](&ones{}, 22050, set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples := make([]beep.Stereo[float64], 200)
+	b.Stream(samples)
+	// the level of the impulse responses is kept when resampling
+	if math.Abs(samples[199][0]-1) > 0.05 {
+		t.Errorf("got %v, want about 1", samples[199][0])
+	}
+}
+
+func TestNewBinauralErrors(t *testing.T) {
+	uneven := impulseSet()
+	uneven.HRIRs[3].Right = uneven.HRIRs[3].Right[:4]
+	for _, set := range []*spatial.HRIRSet{{SampleRate: 44100}, uneven} {
+		if _, err := spatial.NewBinaural[float64, beep.Stereo[float64]](&ones{}, 44100, set); err == nil {
+			t.Errorf("%d HRIRs: expected an error", len(set.HRIRs))
+		}
+		if _, err := spatial.NewBinauralEmitter[float64, beep.Stereo[float64]](&ones{}, 44100, spatial.NewListener(), set); err == nil {
+			t.Errorf("%d HRIRs: expected an error from NewBinauralEmitter", len(set.HRIRs))
+		}
+	}
+}
+
+func TestBinauralAllocs(t *testing.T) {
+	b, err := spatial.NewBinaural[float64, beep.Stereo[float64]](&ones{}, 44100, impulseSet())
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples := make([]beep.Stereo[float64], 512)
+	b.Stream(samples)
+	azimuth := 0.0
+	allocs := testing.AllocsPerRun(100, func() {
+		// a new direction every time
+		azimuth += 0.01
+		b.SetDirection(azimuth, 0)
+		b.Stream(samples)
+	})
+	if allocs != 0 {
+		t.Errorf("Stream allocates %v times per call", allocs)
+	}
+}
@@ -0,0 +1,92 @@
+package spatial
+
+import (
+	"math"
+	"sync"
+)
+
+// DefaultSpeedOfSound is the speed of sound in air in meters per second.
+const DefaultSpeedOfSound = 343.3
+
+// ListenerParams are the parameters of a Listener.
+type ListenerParams struct {
+	Position Vec3
+	Velocity Vec3
+	// Forward and Up are the orientation of the Listener. They don't have to be normalized.
+	Forward, Up Vec3
+	// SpeedOfSound in meters per second sets the strength of the Doppler effect.
+	SpeedOfSound float64
+}
+
+// Listener is the point of view from which the Emitters are heard. All its methods are safe to
+// call from multiple goroutines.
+type Listener struct {
+	mu     sync.Mutex
+	params ListenerParams
+}
+
+// NewListener returns a Listener at the origin facing -Z with Y up, at rest, with the
+// DefaultSpeedOfSound.
+func NewListener() *Listener {
+	return &Listener{params: ListenerParams{
+		Forward:      Vec3{0, 0, -1},
+		Up:           Vec3{0, 1, 0},
+		SpeedOfSound: DefaultSpeedOfSound,
+	}}
+}
+
+// Params returns the current parameters of the Listener.
+func (l *Listener) Params() ListenerParams {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.params
+}
+
+// SetParams sets all the parameters of the Listener at once.
+func (l *Listener) SetParams(p ListenerParams) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.params = p
+}
+
+// SetPosition moves the Listener to pos.
+func (l *Listener) SetPosition(pos Vec3) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.params.Position = pos
+}
+
+// SetVelocity sets the velocity of the Listener, which is used for the Doppler effect.
+func (l *Listener) SetVelocity(vel Vec3) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.params.Velocity = vel
+}
+
+// SetOrientation turns the Listener to face forward with the top of its head towards up.
+func (l *Listener) SetOrientation(forward, up Vec3) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.params.Forward, l.params.Up = forward, up
+}
+
+// Direction returns where the point pos is as heard by a Listener with the parameters p. The
+// azimuth is the angle in radians from the forward direction, positive to the right, between -π
+// and π. The elevation is the angle in radians above the horizontal plane of the Listener, between
+// -π/2 and π/2. The distance is in meters. A point at the position of the Listener is in front of
+// it.
+func (p ListenerParams) Direction(pos Vec3) (azimuth, elevation, distance float64) {
+	rel := pos.Sub(p.Position)
+	distance = rel.Len()
+	if distance == 0 {
+		return 0, 0, 0
+	}
+	forward := p.Forward.Unit()
+	right := forward.Cross(p.Up).Unit()
+	up := right.Cross(forward)
+
+	x, y, z := rel.Dot(right), rel.Dot(up), rel.Dot(forward)
+	azimuth = math.Atan2(x, z)
+	elevation = math.Asin(math.Max(-1, math.Min(1, y/distance)))
+	return azimuth, elevation, distance
+}
@@ -0,0 +1,37 @@
+package spatial_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/beep/spatial"
+)
+
+func TestListenerDirection(t *testing.T) {
+	l := spatial.NewListener()
+	l.SetPosition(spatial.Vec3{X: 1, Y: 1, Z: 1})
+	tests := []struct {
+		pos                spatial.Vec3
+		azimuth, elevation float64
+		distance           float64
+	}{
+		{spatial.Vec3{X: 1, Y: 1, Z: -1}, 0, 0, 2},
+		{spatial.Vec3{X: 3, Y: 1, Z: 1}, math.Pi / 2, 0, 2},
+		{spatial.Vec3{X: -1, Y: 1, Z: 1}, -math.Pi / 2, 0, 2},
+		{spatial.Vec3{X: 1, Y: 1, Z: 3}, math.Pi, 0, 2},
+		{spatial.Vec3{X: 1, Y: 3, Z: 1}, 0, math.Pi / 2, 2},
+		{spatial.Vec3{X: 2, Y: 1, Z: 0}, math.Pi / 4, 0, math.Sqrt2},
+	}
+	for _, test := range tests {
+		az, el, d := l.Params().Direction(test.pos)
+		if math.Abs(az-test.azimuth) > 1e-12 || math.Abs(el-test.elevation) > 1e-12 || math.Abs(d-test.distance) > 1e-12 {
+			t.Errorf("%+v: got %v, %v, %v, want %v, %v, %v", test.pos, az, el, d, test.azimuth, test.elevation, test.distance)
+		}
+	}
+
+	// turned to the right, the point in front of the original orientation is on the left
+	l.SetOrientation(spatial.Vec3{X: 1}, spatial.Vec3{Y: 1})
+	if az, _, _ := l.Params().Direction(spatial.Vec3{X: 1, Y: 1, Z: -1}); math.Abs(az+math.Pi/2) > 1e-12 {
+		t.Errorf("turned right: got azimuth %v, want -π/2", az)
+	}
+}
@@ -0,0 +1,432 @@
+package spatial
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+)
+
+// Wall is one of the six walls of a Room.
+type Wall int
+
+const (
+	// WallLeft is the wall at X = 0.
+	WallLeft Wall = iota
+	// WallRight is the wall at X = Size.X.
+	WallRight
+	// WallFloor is the wall at Y = 0.
+	WallFloor
+	// WallCeiling is the wall at Y = Size.Y.
+	WallCeiling
+	// WallFront is the wall at Z = 0, in front of a Listener facing -Z.
+	WallFront
+	// WallBack is the wall at Z = Size.Z.
+	WallBack
+)
+
+// String returns the name of the wall.
+func (w Wall) String() string {
+	switch w {
+	case WallLeft:
+		return "WallLeft"
+	case WallRight:
+		return "WallRight"
+	case WallFloor:
+		return "WallFloor"
+	case WallCeiling:
+		return "WallCeiling"
+	case WallFront:
+		return "WallFront"
+	case WallBack:
+		return "WallBack"
+	}
+	return fmt.Sprintf("Wall(%d)", int(w))
+}
+
+// Room is a rectangular room, a shoebox, which spans from the origin to Size.
+type Room struct {
+	// Size is the width (X), the height (Y) and the depth (Z) of the room in meters.
+	Size Vec3
+	// Absorption is the fraction of the energy absorbed by each Wall, indexed by Wall, between 0
+	// (a hard wall which reflects everything) and 1 (an open window). For example, concrete absorbs
+	// about 0.02, carpet 0.3 and acoustic panels 0.8.
+	Absorption [6]float64
+	// Order is the maximal number of reflections of the simulated early reflections, for example
+	// 3. The number of image sources grows with its cube.
+	Order int
+	// Reverb is the level of the late reverberation tail, which follows the early reflections,
+	// usually between 0 and 1. Zero disables it.
+	Reverb float64
+}
+
+// ImageSource is a mirror image of a source in the walls of a Room. The sound reflected by the
+// walls is heard as if it came straight from the image source.
+type ImageSource struct {
+	Position Vec3
+	// Reflection is the gain of the reflections on the walls.
+	Reflection float64
+	// Order is the number of reflections, 0 for the source itself.
+	Order int
+}
+
+// ImageSources returns the image sources of a source at pos up to the Order of the room,
+// including the source itself, which comes first. Positions outside the room are moved to the
+// nearest wall.
+func (r Room) ImageSources(pos Vec3) []ImageSource {
+	return r.imageSources(nil, pos)
+}
+
+// imageSources appends the image sources of a source at pos to dst[:0] and returns it, which
+// doesn't allocate if dst has enough room for them.
+func (r Room) imageSources(dst []ImageSource, pos Vec3) []ImageSource {
+	pos = r.clamp(pos)
+	images := dst[:0]
+	for order := 0; order <= r.Order; order++ {
+		for nx := -order; nx <= order; nx++ {
+			for ny := -(order - abs(nx)); ny <= order-abs(nx); ny++ {
+				// both signs of the remaining reflections along Z, once if there are none
+				nz := -(order - abs(nx) - abs(ny))
+				for {
+					x, gx := r.mirror(pos.X, r.Size.X, nx, WallLeft)
+					y, gy := r.mirror(pos.Y, r.Size.Y, ny, WallFloor)
+					z, gz := r.mirror(pos.Z, r.Size.Z, nz, WallFront)
+					images = append(images, ImageSource{Vec3{x, y, z}, gx * gy * gz, order})
+					if nz >= 0 {
+						break
+					}
+					nz = -nz
+				}
+			}
+		}
+	}
+	return images
+}
+
+// mirror returns the coordinate of the n-th image of x along an axis of the length size, and the
+// gain of the reflections on the walls low (at 0) and low+1 (at size).
+func (r Room) mirror(x, size float64, n int, low Wall) (float64, float64) {
+	// every image reflects alternately on both walls, starting with the high one for positive n
+	hits := abs(n)
+	highHits, lowHits := (hits+1)/2, hits/2
+	if n < 0 {
+		highHits, lowHits = lowHits, highHits
+	}
+	gain := math.Pow(math.Sqrt(1-r.absorption(low)), float64(lowHits)) *
+		math.Pow(math.Sqrt(1-r.absorption(low+1)), float64(highHits))
+
+	if n%2 == 0 {
+		return float64(n)*size + x, gain
+	}
+	return float64(n+1)*size - x, gain
+}
+
+func (r Room) absorption(w Wall) float64 {
+	return math.Max(0, math.Min(1, r.Absorption[w]))
+}
+
+// clamp moves pos inside the room.
+func (r Room) clamp(pos Vec3) Vec3 {
+	return Vec3{
+		math.Max(0, math.Min(r.Size.X, pos.X)),
+		math.Max(0, math.Min(r.Size.Y, pos.Y)),
+		math.Max(0, math.Min(r.Size.Z, pos.Z)),
+	}
+}
+
+// RT60 returns the reverberation time of the room in seconds, the time in which the late
+// reverberation decays by 60 dB, after Sabine's formula. A room which absorbs nothing reverberates
+// forever.
+func (r Room) RT60() float64 {
+	x, y, z := r.Size.X, r.Size.Y, r.Size.Z
+	areas := [6]float64{y * z, y * z, x * z, x * z, x * y, x * y}
+	var absorption float64
+	for w, area := range areas {
+		absorption += area * r.absorption(Wall(w))
+	}
+	if absorption == 0 {
+		return math.Inf(1)
+	}
+	return 0.161 * x * y * z / absorption
+}
+
+// meanFreePath returns the average distance sound travels in the room between two reflections.
+func (r Room) meanFreePath() float64 {
+	x, y, z := r.Size.X, r.Size.Y, r.Size.Z
+	return 4 * x * y * z / (2 * (x*y + x*z + y*z))
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// roomTailSilence is the level below which the tail of a RoomEmitter is considered decayed
+// (-100 dB).
+const roomTailSilence = 1e-5
+
+// maxRT60 limits the reverberation time of rooms which absorb (almost) nothing.
+const maxRT60 = 20
+
+// RoomEmitter is a sound source in a Room, heard by a Listener inside the same room. It streams
+// the wrapped Streamer mixed down to mono with its early reflections, simulated by the image-source
+// method, and the late reverberation of the room in the first two channels.
+//
+// Each image source is heard after the time the sound takes to travel from it, attenuated by the
+// reflections and by the distance with the inverse distance law from 1 meter, and panned by its
+// azimuth with an equal-power pan law. When the RoomEmitter or the Listener move, the delays and
+// the gains change smoothly over the next call to Stream, which also makes for a natural Doppler
+// effect. The late reverberation is a feedback delay network tuned to the RT60 of the room.
+//
+// After the wrapped Streamer is drained, the RoomEmitter keeps streaming the reflections and the
+// reverberation until they decay to silence.
+//
+// Position and SetPosition are safe to call from multiple goroutines.
+type RoomEmitter[S beep.Size, P beep.Point[S]] struct {
+	s        beep.Streamer[S, P]
+	sr       beep.SampleRate
+	listener *Listener
+	room     Room
+
+	mu       sync.Mutex
+	position Vec3
+
+	history []float64 // the input mixed down to mono
+	pos     int       // position of the next input sample in history
+	images  []ImageSource
+	taps    []roomTap // taps of the last Stream
+	next    []roomTap
+	late    *lateReverb
+	started bool
+	drained bool
+	quiet   int // consecutive samples of silent output after the Streamer is drained
+}
+
+// roomTap is one image source as heard by the listener.
+type roomTap struct {
+	delay       float64 // in samples
+	left, right float64
+}
+
+// NewRoomEmitter returns a RoomEmitter of s with the sample rate sr in the room, heard by the
+// listener. It starts at the origin. If any dimension of the Size of the room isn't positive, or
+// its Order is negative, NewRoomEmitter panics.
+//
+// The returned RoomEmitter propagates s's errors through Err.
+func NewRoomEmitter[S beep.Size, P beep.Point[S]](s beep.Streamer[S, P], sr beep.SampleRate, listener *Listener, room Room) *RoomEmitter[S, P] {
+	if !(room.Size.X > 0 && room.Size.Y > 0 && room.Size.Z > 0) {
+		panic(fmt.Errorf("spatial: room: invalid size: %v", room.Size))
+	}
+	if room.Order < 0 {
+		panic(fmt.Errorf("spatial: room: invalid order: %d", room.Order))
+	}
+	// sound from the farthest image source must fit in the history, as well as the input of the
+	// late reverberation, which starts after the early reflections
+	longest := float64(room.Order+2)*room.Size.Len() + float64(room.Order)*room.meanFreePath()
+	e := &RoomEmitter[S, P]{
+		s:        s,
+		sr:       sr,
+		listener: listener,
+		room:     room,
+		history:  make([]float64, int(math.Ceil(longest/DefaultSpeedOfSound*float64(sr)))+4),
+	}
+	// the number of image sources doesn't change, so Stream doesn't allocate
+	e.images = room.imageSources(nil, Vec3{})
+	e.taps = make([]roomTap, 0, len(e.images))
+	e.next = make([]roomTap, 0, len(e.images))
+	if room.Reverb > 0 {
+		e.late = newLateReverb(room, sr)
+	}
+	return e
+}
+
+// Position returns the position of the RoomEmitter.
+func (e *RoomEmitter[S, P]) Position() Vec3 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.position
+}
+
+// SetPosition moves the RoomEmitter to pos. Positions outside the room are moved to the nearest
+// wall.
+func (e *RoomEmitter[S, P]) SetPosition(pos Vec3) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.position = pos
+}
+
+// Stream streams the wrapped Streamer as heard by the Listener in the room.
+func (e *RoomEmitter[S, P]) Stream(samples []P) (n int, ok bool) {
+	e.updateTaps()
+	limit := len(e.history)
+	if e.late != nil {
+		limit += e.late.longest()
+	}
+
+	for n < len(samples) {
+		if !e.drained {
+			sn, sok := e.s.Stream(samples[n:])
+			if !sok {
+				e.drained = true
+				if e.s.Err() != nil {
+					// no tail on error
+					e.quiet = limit + 1
+				}
+				continue
+			}
+			e.render(samples[n:n+sn], n, len(samples))
+			n += sn
+			continue
+		}
+		if e.quiet > limit {
+			break
+		}
+		// stream the tail sample by sample, so it stops right after it decays
+		var zero P
+		samples[n] = zero
+		level := e.render(samples[n:n+1], n, len(samples))
+		if level < roomTailSilence {
+			e.quiet++
+		} else {
+			e.quiet = 0
+		}
+		n++
+	}
+	if n > 0 {
+		e.taps, e.next = e.next, e.taps
+	}
+	return n, n > 0
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (e *RoomEmitter[S, P]) Err() error {
+	return e.s.Err()
+}
+
+// updateTaps calculates the taps for the current positions into next.
+func (e *RoomEmitter[S, P]) updateTaps() {
+	lp := e.listener.Params()
+	lp.Position = e.room.clamp(lp.Position)
+	c := lp.SpeedOfSound
+	if c <= 0 {
+		c = DefaultSpeedOfSound
+	}
+
+	e.images = e.room.imageSources(e.images, e.Position())
+	e.next = e.next[:0]
+	for _, img := range e.images {
+		azimuth, _, dist := lp.Direction(img.Position)
+		gain := img.Reflection / math.Max(1, dist)
+		t := (math.Sin(azimuth) + 1) / 2
+		e.next = append(e.next, roomTap{
+			delay: math.Min(dist/c*float64(e.sr), float64(len(e.history)-2)),
+			left:  gain * math.Cos(t*math.Pi/2),
+			right: gain * math.Sin(t*math.Pi/2),
+		})
+	}
+	if !e.started {
+		e.taps, e.started = append(e.taps[:0], e.next...), true
+	}
+}
+
+// render replaces samples, which are at the offset of the total samples of the Stream call, by
+// the sound heard in the room, and returns the peak level of the output.
+func (e *RoomEmitter[S, P]) render(samples []P, offset, total int) (level float64) {
+	// the late reverberation starts after the early reflections
+	lateDelay := e.next[0].delay + float64(e.room.Order)*e.room.meanFreePath()/DefaultSpeedOfSound*float64(e.sr)
+	lateDelay = math.Min(lateDelay, float64(len(e.history)-2))
+
+	i := offset
+	points.Each(samples, func(ch []S) {
+		var mono float64
+		for _, x := range ch {
+			mono += float64(x)
+		}
+		e.history[e.pos] = mono / float64(len(ch))
+		e.pos = (e.pos + 1) % len(e.history)
+
+		// ramp the taps linearly over the streamed samples
+		i++
+		f := float64(i) / float64(total)
+		var l, r float64
+		for k, to := range e.next {
+			from := e.taps[k]
+			x := e.read(from.delay + f*(to.delay-from.delay))
+			l += x * (from.left + f*(to.left-from.left))
+			r += x * (from.right + f*(to.right-from.right))
+		}
+		if e.late != nil {
+			ll, lr := e.late.process(e.read(lateDelay) * e.room.Reverb)
+			l, r = l+ll, r+lr
+		}
+
+		level = math.Max(level, math.Max(math.Abs(l), math.Abs(r)))
+		if len(ch) == 1 {
+			ch[0] = S((l + r) / math.Sqrt2)
+			return
+		}
+		ch[0], ch[1] = S(l), S(r)
+		for c := 2; c < len(ch); c++ {
+			ch[c] = 0
+		}
+	})
+	return level
+}
+
+// read returns the input from d samples before the last written one, interpolated linearly.
+func (e *RoomEmitter[S, P]) read(d float64) float64 {
+	k := int(d)
+	frac := d - float64(k)
+	at := func(k int) float64 {
+		return e.history[((e.pos-1-k)%len(e.history)+len(e.history))%len(e.history)]
+	}
+	return at(k) + frac*(at(k+1)-at(k))
+}
+
+// lateReverbFactors are the lengths of the delay lines of the late reverberation relative to the
+// mean free path, chosen not to share common factors.
+var lateReverbFactors = [4]float64{1, 1.31, 1.59, 1.87}
+
+// lateReverb is a feedback delay network of four delay lines with a Householder feedback matrix.
+type lateReverb struct {
+	lines [4][]float64
+	pos   [4]int
+	gains [4]float64 // feedback gains, which set the decay
+}
+
+func newLateReverb(room Room, sr beep.SampleRate) *lateReverb {
+	rt60 := math.Min(room.RT60(), maxRT60)
+	base := room.meanFreePath() / DefaultSpeedOfSound * float64(sr)
+	l := &lateReverb{}
+	for k, factor := range lateReverbFactors {
+		d := int(math.Max(float64(k+1), math.Round(base*factor)))
+		l.lines[k] = make([]float64, d)
+		l.gains[k] = math.Pow(10, -3*float64(d)/(float64(sr)*rt60))
+	}
+	return l
+}
+
+// longest returns the length of the longest delay line.
+func (l *lateReverb) longest() int {
+	return len(l.lines[3])
+}
+
+// process feeds x into the network and returns the output of the left and the right channel.
+func (l *lateReverb) process(x float64) (left, right float64) {
+	var y [4]float64
+	var sum float64
+	for k := range y {
+		y[k] = l.lines[k][l.pos[k]]
+		sum += y[k]
+	}
+	for k := range y {
+		sign := float64(1 - 2*(k%2))
+		l.lines[k][l.pos[k]] = l.gains[k]*(y[k]-sum/2) + sign*x/2
+		l.pos[k] = (l.pos[k] + 1) % len(l.lines[k])
+	}
+	return y[0] - y[1] + y[2] - y[3], y[0] + y[1] - y[2] - y[3]
+}
@@ -0,0 +1,207 @@
+package spatial_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/spatial"
+)
+
+func TestRoomImageSources(t *testing.T) {
+	room := spatial.Room{Size: spatial.Vec3{X: 4, Y: 3, Z: 5}, Order: 2}
+	room.Absorption[spatial.WallLeft] = 0.75
+
+	images := room.ImageSources(spatial.Vec3{X: 1, Y: 1, Z: 1})
+	if len(images) != 25 {
+		t.Fatalf("got %d image sources, want 25", len(images))
+	}
+	if images[0].Position != (spatial.Vec3{X: 1, Y: 1, Z: 1}) || images[0].Reflection != 1 || images[0].Order != 0 {
+		t.Errorf("got %+v first, want the source itself", images[0])
+	}
+
+	want := map[spatial.Vec3]float64{
+		{X: -1, Y: 1, Z: 1}: 0.5, // left wall
+		{X: 7, Y: 1, Z: 1}:  1,   // right wall
+		{X: 1, Y: -1, Z: 1}: 1,   // floor
+		{X: 1, Y: 5, Z: 1}:  1,   // ceiling
+		{X: 1, Y: 1, Z: -1}: 1,   // front wall
+		{X: 1, Y: 1, Z: 9}:  1,   // back wall
+		{X: 9, Y: 1, Z: 1}:  0.5, // right, then left wall
+		{X: -7, Y: 1, Z: 1}: 0.5, // left, then right wall
+	}
+	for _, img := range images {
+		if g, ok := want[img.Position]; ok {
+			if math.Abs(img.Reflection-g) > 1e-12 {
+				t.Errorf("%v: got the reflection %v, want %v", img.Position, img.Reflection, g)
+			}
+			delete(want, img.Position)
+		}
+	}
+	for pos := range want {
+		t.Errorf("missing image source at %v", pos)
+	}
+}
+
+func TestRoomRT60(t *testing.T) {
+	room := spatial.Room{Size: spatial.Vec3{X: 10, Y: 10, Z: 10}}
+	if !math.IsInf(room.RT60(), 1) {
+		t.Errorf("got %v without absorption, want +Inf", room.RT60())
+	}
+	for w := range room.Absorption {
+		room.Absorption[w] = 0.1
+	}
+	// 0.161 * 1000 / (600 * 0.1)
+	if rt60 := room.RT60(); math.Abs(rt60-2.6833) > 1e-3 {
+		t.Errorf("got %v, want 2.683", rt60)
+	}
+}
+
+func TestRoomEmitterReflections(t *testing.T) {
+	const sr = 44100
+	room := spatial.Room{Size: spatial.Vec3{X: 10, Y: 10, Z: 10}, Order: 1}
+	for w := range room.Absorption {
+		room.Absorption[w] = 1
+	}
+	room.Absorption[spatial.WallLeft] = 0
+
+	l := spatial.NewListener()
+	l.SetPosition(spatial.Vec3{X: 2, Y: 5, Z: 5})
+	e := spatial.NewRoomEmitter[float64, beep.Stereo[float64]](impulse(1), sr, l, room)
+	e.SetPosition(spatial.Vec3{X: 2, Y: 5, Z: 2})
+
+	out := collect(e)
+
+	// the direct sound from 3 meters in front and the reflection on the left wall from 5 meters
+	direct := 3 / spatial.DefaultSpeedOfSound * sr
+	reflection := 5 / spatial.DefaultSpeedOfSound * sr
+	sum := func(from, to float64) (l, r float64) {
+		for i := int(from) - 2; i <= int(to)+2; i++ {
+			l += out[i][0]
+			r += out[i][1]
+		}
+		return l, r
+	}
+	if dl, dr := sum(direct, direct); math.Abs(dl-math.Sqrt2/6) > 1e-9 || math.Abs(dr-math.Sqrt2/6) > 1e-9 {
+		t.Errorf("direct sound: got %v %v, want %v in both channels", dl, dr, math.Sqrt2/6)
+	}
+	// the image source is 4 meters to the left and 3 meters to the front
+	pan := (1 - 4.0/5) / 2
+	rl, rr := sum(reflection, reflection)
+	if math.Abs(rl-math.Cos(pan*math.Pi/2)/5) > 1e-9 || math.Abs(rr-math.Sin(pan*math.Pi/2)/5) > 1e-9 {
+		t.Errorf("reflection: got %v %v", rl, rr)
+	}
+	if ml, mr := sum(direct+4, reflection-4); ml != 0 || mr != 0 {
+		t.Errorf("got sound between the direct sound and the reflection")
+	}
+	if len(out) > sr/4 {
+		t.Errorf("got a tail of %d samples without reverberation", len(out))
+	}
+}
+
+func TestRoomEmitterReverb(t *testing.T) {
+	const sr = 44100
+	room := spatial.Room{Size: spatial.Vec3{X: 6, Y: 3, Z: 5}, Order: 2, Reverb: 1}
+	for w := range room.Absorption {
+		room.Absorption[w] = 0.3
+	}
+	l := spatial.NewListener()
+	l.SetPosition(spatial.Vec3{X: 3, Y: 1.5, Z: 4})
+	e := spatial.NewRoomEmitter[float64, beep.Stereo[float64]](impulse(1), sr, l, room)
+	e.SetPosition(spatial.Vec3{X: 3, Y: 1.5, Z: 1})
+
+	out := collect(e)
+	// the tail decays by 100 dB in about 5/3 of the RT60
+	rt60 := room.RT60()
+	if got := float64(len(out)) / sr; got < rt60 || got > 3*rt60 {
+		t.Errorf("got a tail of %v s, want about %v s", got, 5*rt60/3)
+	}
+	for _, p := range out {
+		if math.IsNaN(p[0]) || math.Abs(p[0]) > 1 || math.Abs(p[1]) > 1 {
+			t.Fatalf("got %v", p)
+		}
+	}
+}
+
+func TestRoomEmitterMoves(t *testing.T) {
+	const sr = 44100
+	room := spatial.Room{Size: spatial.Vec3{X: 10, Y: 10, Z: 10}}
+	l := spatial.NewListener()
+	l.SetPosition(spatial.Vec3{X: 5, Y: 5, Z: 5})
+	e := spatial.NewRoomEmitter[float64, beep.Stereo[float64]](&ones{}, sr, l, room)
+
+	samples := make([]beep.Stereo[float64], 2000)
+	e.SetPosition(spatial.Vec3{X: 5, Y: 5, Z: 3})
+	e.Stream(samples)
+	e.Stream(samples)
+	if g := samples[1999][0] + samples[1999][1]; math.Abs(g-math.Sqrt2/2) > 1e-9 {
+		t.Errorf("at 2 meters: got %v, want %v", g, math.Sqrt2/2)
+	}
+
+	// moving further away ramps the gain down over the next Stream
+	e.SetPosition(spatial.Vec3{X: 5, Y: 5, Z: 1})
+	e.Stream(samples)
+	first, last := samples[0][0]+samples[0][1], samples[1999][0]+samples[1999][1]
+	if first < last || math.Abs(last-math.Sqrt2/4) > 1e-9 {
+		t.Errorf("got %v at the start and %v at the end, want a ramp to %v", first, last, math.Sqrt2/4)
+	}
+}
+
+func TestRoomEmitterAllocs(t *testing.T) {
+	room := spatial.Room{Size: spatial.Vec3{X: 6, Y: 3, Z: 5}, Order: 3, Reverb: 0.5}
+	for w := range room.Absorption {
+		room.Absorption[w] = 0.3
+	}
+	l := spatial.NewListener()
+	l.SetPosition(spatial.Vec3{X: 3, Y: 1.5, Z: 4})
+	e := spatial.NewRoomEmitter[float64, beep.Stereo[float64]](&ones{}, 44100, l, room)
+	samples := make([]beep.Stereo[float64], 512)
+	e.Stream(samples)
+	x := 1.0
+	allocs := testing.AllocsPerRun(100, func() {
+		// a new position every time
+		x += 0.01
+		e.SetPosition(spatial.Vec3{X: x, Y: 1.5, Z: 1})
+		e.Stream(samples)
+	})
+	if allocs != 0 {
+		t.Errorf("Stream allocates %v times per call", allocs)
+	}
+}
+
+func TestNewRoomEmitterPanics(t *testing.T) {
+	for _, room := range []spatial.Room{
+		{Size: spatial.Vec3{X: 4, Z: 5}},
+		{Size: spatial.Vec3{X: 4, Y: 3, Z: 5}, Order: -1},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%+v: expected a panic", room)
+				}
+			}()
+			spatial.NewRoomEmitter[float64, beep.Stereo[float64]](&ones{}, 44100, spatial.NewListener(), room)
+		}()
+	}
+}
+
+func TestWallString(t *testing.T) {
+	if s := spatial.WallCeiling.String(); s != "WallCeiling" {
+		t.Errorf("got %q", s)
+	}
+	if s := spatial.Wall(9).String(); s != "Wall(9)" {
+		t.Errorf("got %q", s)
+	}
+}
+
+func collect(s beep.Streamer[float64, beep.Stereo[float64]]) []beep.Stereo[float64] {
+	var out []beep.Stereo[float64]
+	buf := make([]beep.Stereo[float64], 512)
+	for {
+		n, ok := s.Stream(buf)
+		if !ok {
+			return out
+		}
+		out = append(out, buf[:n]...)
+	}
+}
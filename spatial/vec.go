@@ -0,0 +1,51 @@
+package spatial
+
+import "math"
+
+// Vec3 is a 3D vector.
+type Vec3 struct {
+	X, Y, Z float64
+}
+
+// Add returns v+u.
+func (v Vec3) Add(u Vec3) Vec3 {
+	return Vec3{v.X + u.X, v.Y + u.Y, v.Z + u.Z}
+}
+
+// Sub returns v-u.
+func (v Vec3) Sub(u Vec3) Vec3 {
+	return Vec3{v.X - u.X, v.Y - u.Y, v.Z - u.Z}
+}
+
+// Scale returns v multiplied by s.
+func (v Vec3) Scale(s float64) Vec3 {
+	return Vec3{v.X * s, v.Y * s, v.Z * s}
+}
+
+// Dot returns the dot product of v and u.
+func (v Vec3) Dot(u Vec3) float64 {
+	return v.X*u.X + v.Y*u.Y + v.Z*u.Z
+}
+
+// Cross returns the cross product of v and u.
+func (v Vec3) Cross(u Vec3) Vec3 {
+	return Vec3{
+		v.Y*u.Z - v.Z*u.Y,
+		v.Z*u.X - v.X*u.Z,
+		v.X*u.Y - v.Y*u.X,
+	}
+}
+
+// Len returns the length of v.
+func (v Vec3) Len() float64 {
+	return math.Sqrt(v.Dot(v))
+}
+
+// Unit returns v scaled to the length of 1. The zero vector stays zero.
+func (v Vec3) Unit() Vec3 {
+	l := v.Len()
+	if l == 0 {
+		return Vec3{}
+	}
+	return v.Scale(1 / l)
+}
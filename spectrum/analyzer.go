@@ -0,0 +1,176 @@
+package spectrum
+
+import (
+	"fmt"
+	"math/cmplx"
+	"sync"
+
+	"github.com/faiface/beep"
+)
+
+// Analyzer is a Streamer which streams the wrapped Streamer unchanged, while computing the
+// magnitude spectrum of each channel every hop samples. The spectra are taken over the last size
+// samples.
+//
+// Magnitudes are scaled so that a full-scale sine wave which falls exactly on a bin reads as 1.
+//
+// Unlike most Streamers, Analyzer may be read from other goroutines while it's being streamed, so
+// visualizers don't have to lock the speaker.
+//
+//	a := spectrum.NewAnalyzer[float64, beep.Stereo[float64]](s, 2048, 512, nil)
+//	speaker.Play(a)
+//	// ... in the drawing goroutine
+//	mags = a.Magnitudes(mags)
+type Analyzer[S beep.Size, P beep.Point[S]] struct {
+	s      beep.Streamer[S, P]
+	fft    *FFT[S]
+	window []float64
+	scale  []float64 // per bin magnitude normalization
+	hist   [][]S     // per channel history of the last size samples, used as a ring buffer
+	pos    int       // write position in hist
+	count  int       // number of samples since the last spectrum
+	hop    int       // number of samples between spectra
+	frame  []S       // windowed frame handed to fft
+	bins   []complex128
+	work   [][]float64 // freshly computed magnitudes, published under mu
+
+	mu     sync.Mutex
+	mags   [][]float64
+	frames int
+}
+
+// NewAnalyzer returns an Analyzer of s, which computes spectra of size samples every hop samples.
+// The size must be a power of two and hop must be positive, otherwise NewAnalyzer panics.
+//
+// The window must either have the length of size, or be nil, in which case the Hann window is
+// used.
+func NewAnalyzer[S beep.Size, P beep.Point[S]](s beep.Streamer[S, P], size, hop int, window []float64) *Analyzer[S, P] {
+	if hop <= 0 {
+		panic(fmt.Errorf("spectrum: invalid hop size: %d", hop))
+	}
+	if window == nil {
+		window = Hann(size)
+	}
+	if len(window) != size {
+		panic(fmt.Errorf("spectrum: window length %d does not match size %d", len(window), size))
+	}
+	var p P
+	ct := p.Count()
+
+	a := &Analyzer[S, P]{
+		s:      s,
+		fft:    NewFFT[S](size),
+		window: window,
+		hist:   make([][]S, ct),
+		hop:    hop,
+		frame:  make([]S, size),
+		work:   make([][]float64, ct),
+		mags:   make([][]float64, ct),
+	}
+	a.bins = make([]complex128, a.fft.Bins())
+	for c := 0; c < ct; c++ {
+		a.hist[c] = make([]S, size)
+		a.work[c] = make([]float64, len(a.bins))
+		a.mags[c] = make([]float64, len(a.bins))
+	}
+
+	var sum float64
+	for _, w := range window {
+		sum += w
+	}
+	a.scale = make([]float64, len(a.bins))
+	for k := range a.scale {
+		if sum == 0 {
+			continue
+		}
+		a.scale[k] = 2 / sum
+		if k == 0 || k == len(a.scale)-1 {
+			a.scale[k] = 1 / sum
+		}
+	}
+	return a
+}
+
+// Stream streams the wrapped Streamer unchanged and updates the spectra.
+func (a *Analyzer[S, P]) Stream(samples []P) (n int, ok bool) {
+	n, ok = a.s.Stream(samples)
+	for i := range samples[:n] {
+		for c := range a.hist {
+			a.hist[c][a.pos] = samples[i].Get(c)
+		}
+		a.pos = (a.pos + 1) % len(a.frame)
+		a.count++
+		if a.count >= a.hop {
+			a.count = 0
+			a.analyze()
+		}
+	}
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (a *Analyzer[S, P]) Err() error {
+	return a.s.Err()
+}
+
+// Size returns the number of samples each spectrum is computed over.
+func (a *Analyzer[S, P]) Size() int {
+	return len(a.frame)
+}
+
+// Bins returns the number of bins of each spectrum, which is Size()/2+1.
+func (a *Analyzer[S, P]) Bins() int {
+	return len(a.bins)
+}
+
+// Frames returns the number of spectra computed so far. It can be used to tell if Magnitudes
+// changed since the last reading.
+func (a *Analyzer[S, P]) Frames() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.frames
+}
+
+// Magnitudes copies the latest magnitude spectra, one per channel, into dst and returns it. If dst
+// doesn't have the right shape, a new slice is allocated, so passing the previously returned slice
+// avoids allocations.
+//
+// Until the first spectrum is computed, the magnitudes are all zero.
+func (a *Analyzer[S, P]) Magnitudes(dst [][]float64) [][]float64 {
+	if len(dst) != len(a.hist) {
+		dst = make([][]float64, len(a.hist))
+	}
+	for c := range dst {
+		if len(dst[c]) != len(a.bins) {
+			dst[c] = make([]float64, len(a.bins))
+		}
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for c := range dst {
+		copy(dst[c], a.mags[c])
+	}
+	return dst
+}
+
+func (a *Analyzer[S, P]) analyze() {
+	for c, hist := range a.hist {
+		// the oldest sample is at the write position
+		n := copy(a.frame, hist[a.pos:])
+		copy(a.frame[n:], hist[:a.pos])
+		for i := range a.frame {
+			a.frame[i] *= S(a.window[i])
+		}
+		a.fft.Forward(a.bins, a.frame)
+		for k, b := range a.bins {
+			a.work[c][k] = cmplx.Abs(b) * a.scale[k]
+		}
+	}
+
+	a.mu.Lock()
+	for c := range a.mags {
+		copy(a.mags[c], a.work[c])
+	}
+	a.frames++
+	a.mu.Unlock()
+}
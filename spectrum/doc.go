@@ -0,0 +1,2 @@
+// Package spectrum provides frequency-domain tools for the Beep library.
+package spectrum
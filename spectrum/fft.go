@@ -0,0 +1,156 @@
+package spectrum
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	"github.com/faiface/beep"
+)
+
+// FFT computes the discrete Fourier transform of real signals of a fixed, power of two length.
+//
+// The spectrum of a real signal of length n is conjugate symmetric, so only its first n/2+1 bins
+// are computed and stored. Bin k corresponds to the frequency k*sampleRate/n.
+//
+// The transform itself is computed in float64 regardless of S. An FFT keeps scratch space and must
+// not be used from multiple goroutines at once.
+type FFT[S beep.Size] struct {
+	n      int
+	tw     []complex128 // twiddle factors of the half-length complex transform
+	rot    []complex128 // e^(-2πik/n), used to split the half-length transform into the real one
+	bitrev []int        // bit reversal permutation of the half-length transform
+	buf    []complex128 // scratch space for the half-length transform
+}
+
+// NewFFT creates an FFT for signals of length n. If n is not a power of two or is less than 2,
+// NewFFT panics.
+func NewFFT[S beep.Size](n int) *FFT[S] {
+	if n < 2 || n&(n-1) != 0 {
+		panic(fmt.Errorf("spectrum: invalid FFT length: %d", n))
+	}
+	m := n / 2
+	f := &FFT[S]{
+		n:      n,
+		tw:     make([]complex128, m/2),
+		rot:    make([]complex128, m),
+		bitrev: make([]int, m),
+		buf:    make([]complex128, m),
+	}
+	for k := range f.tw {
+		f.tw[k] = cmplx.Rect(1, -2*math.Pi*float64(k)/float64(m))
+	}
+	for k := range f.rot {
+		f.rot[k] = cmplx.Rect(1, -2*math.Pi*float64(k)/float64(n))
+	}
+	bits := 0
+	for 1<<bits < m {
+		bits++
+	}
+	for k := range f.bitrev {
+		r := 0
+		for b := 0; b < bits; b++ {
+			if k&(1<<b) != 0 {
+				r |= 1 << (bits - 1 - b)
+			}
+		}
+		f.bitrev[k] = r
+	}
+	return f
+}
+
+// Len returns the length of the signals the FFT transforms.
+func (f *FFT[S]) Len() int {
+	return f.n
+}
+
+// Bins returns the number of frequency bins of a spectrum, which is Len()/2+1.
+func (f *FFT[S]) Bins() int {
+	return f.n/2 + 1
+}
+
+// Forward computes the spectrum of src and stores it in dst. The length of src must be Len() and
+// the length of dst must be at least Bins(), otherwise Forward panics.
+//
+// The spectrum is not scaled.
+func (f *FFT[S]) Forward(dst []complex128, src []S) {
+	if len(src) != f.n || len(dst) < f.Bins() {
+		panic(fmt.Errorf("spectrum: forward: invalid lengths: src %d, dst %d", len(src), len(dst)))
+	}
+	m := f.n / 2
+
+	// pack the even samples into the real part and the odd samples into the imaginary part
+	for k := 0; k < m; k++ {
+		f.buf[k] = complex(float64(src[2*k]), float64(src[2*k+1]))
+	}
+	f.transform(f.buf)
+
+	// split the packed transform into the transforms of the even and the odd samples
+	for k := 0; k <= m; k++ {
+		zk := f.buf[k%m]
+		zc := cmplx.Conj(f.buf[(m-k)%m])
+		even := (zk + zc) / 2
+		odd := (zk - zc) / 2i
+		if k < m {
+			dst[k] = even + f.rot[k]*odd
+		} else {
+			dst[k] = even - odd
+		}
+	}
+}
+
+// Inverse computes the signal from its spectrum src and stores it in dst. The length of dst must
+// be Len() and the length of src must be at least Bins(), otherwise Inverse panics.
+//
+// The result is scaled by 1/Len(), so that Inverse undoes Forward.
+func (f *FFT[S]) Inverse(dst []S, src []complex128) {
+	if len(dst) != f.n || len(src) < f.Bins() {
+		panic(fmt.Errorf("spectrum: inverse: invalid lengths: src %d, dst %d", len(src), len(dst)))
+	}
+	m := f.n / 2
+
+	// merge the spectrum into the transform of the packed samples
+	for k := 0; k < m; k++ {
+		xk := src[k]
+		xc := cmplx.Conj(src[m-k])
+		even := (xk + xc) / 2
+		odd := (xk - xc) / 2 * cmplx.Conj(f.rot[k])
+		// conjugated so that the forward transform computes the inverse one
+		f.buf[k] = cmplx.Conj(even + 1i*odd)
+	}
+	f.transform(f.buf)
+
+	scale := 1 / float64(m)
+	for k := 0; k < m; k++ {
+		z := cmplx.Conj(f.buf[k])
+		dst[2*k] = S(real(z) * scale)
+		dst[2*k+1] = S(imag(z) * scale)
+	}
+}
+
+// transform computes the forward complex transform of x in place.
+func (f *FFT[S]) transform(x []complex128) {
+	m := len(x)
+	for i, j := range f.bitrev {
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	for size := 2; size <= m; size *= 2 {
+		half := size / 2
+		step := m / size
+		for start := 0; start < m; start += size {
+			for k := 0; k < half; k++ {
+				t := f.tw[k*step] * x[start+k+half]
+				x[start+k+half] = x[start+k] - t
+				x[start+k] += t
+			}
+		}
+	}
+}
+
+// BinFrequency returns the center frequency in Hertz of bin k of a spectrum computed by an FFT of
+// length n from a signal sampled at sr.
+func BinFrequency(sr beep.SampleRate, n, k int) float64 {
+	return float64(k) * float64(sr) / float64(n)
+}
@@ -0,0 +1,95 @@
+package spectrum_test
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/spectrum"
+)
+
+func TestFFT(t *testing.T) {
+	t.Run("float64", runTestFFT[float64])
+	t.Run("float32", runTestFFT[float32])
+}
+
+func runTestFFT[S beep.Size](t *testing.T) {
+	delta := 1e-9
+	var check S = 0.1
+	if float64(check) != 0.1 {
+		delta = 1e-4
+	}
+
+	for _, n := range []int{2, 4, 8, 64, 1024} {
+		f := spectrum.NewFFT[S](n)
+		x := make([]S, n)
+		for i := range x {
+			x[i] = S(rand.Float64()*2 - 1)
+		}
+
+		got := make([]complex128, f.Bins())
+		f.Forward(got, x)
+		for k := range got {
+			var want complex128
+			for i, v := range x {
+				want += complex(float64(v), 0) * cmplx.Rect(1, -2*math.Pi*float64(i*k)/float64(n))
+			}
+			if cmplx.Abs(got[k]-want) > delta*float64(n) {
+				t.Fatalf("n=%d: bin %d: got %v, want %v", n, k, got[k], want)
+			}
+		}
+
+		y := make([]S, n)
+		f.Inverse(y, got)
+		for i := range x {
+			if math.Abs(float64(y[i]-x[i])) > delta {
+				t.Fatalf("n=%d: sample %d: got %v, want %v", n, i, y[i], x[i])
+			}
+		}
+	}
+}
+
+func TestAnalyzer(t *testing.T) {
+	const (
+		sr   = beep.SampleRate(8000)
+		size = 256
+		bin  = 16
+	)
+	freq := spectrum.BinFrequency(sr, size, bin)
+
+	var phase float64
+	sine := beep.StreamerFunc[float64, beep.Stereo[float64]](func(samples []beep.Stereo[float64]) (n int, ok bool) {
+		for i := range samples {
+			samples[i] = beep.Stereo[float64]{math.Sin(phase), 0.5 * math.Sin(phase)}
+			phase += 2 * math.Pi * freq / float64(sr)
+		}
+		return len(samples), true
+	})
+	a := spectrum.NewAnalyzer[float64, beep.Stereo[float64]](sine, size, size/4, nil)
+
+	buf := make([]beep.Stereo[float64], 100)
+	for i := 0; i < 10; i++ {
+		a.Stream(buf)
+	}
+	if a.Frames() != 1000/(size/4) {
+		t.Fatalf("got %d frames, want %d", a.Frames(), 1000/(size/4))
+	}
+
+	mags := a.Magnitudes(nil)
+	for c, want := range []float64{1, 0.5} {
+		peak := 0
+		for k := range mags[c] {
+			if mags[c][k] > mags[c][peak] {
+				peak = k
+			}
+		}
+		if peak != bin {
+			t.Errorf("channel %d: peak at bin %d, want %d", c, peak, bin)
+		}
+		if math.Abs(mags[c][peak]-want) > 1e-6 {
+			t.Errorf("channel %d: peak magnitude %f, want %f", c, mags[c][peak], want)
+		}
+	}
+}
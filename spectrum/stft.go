@@ -0,0 +1,171 @@
+package spectrum
+
+import (
+	"fmt"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/internal/points"
+)
+
+// STFT is a Streamer which runs the wrapped Streamer through a short-time Fourier transform. The
+// stream is split into overlapping, windowed frames of size samples, taken every hop samples. The
+// spectrum of each frame is passed to a callback, which may modify it in place. The modified frames
+// are then transformed back and overlap-added to form the output.
+//
+// This is the plumbing shared by spectral effects, such as noise reduction or spectral freeze. A
+// callback which leaves the spectra unchanged results in the original stream delayed by Latency
+// samples.
+//
+// After the wrapped Streamer is drained, STFT streams another Latency samples, so that no part of
+// the processed stream is lost.
+type STFT[S beep.Size, P beep.Point[S]] struct {
+	s       beep.Streamer[S, P]
+	process func(channel int, bins []complex128)
+	fft     *FFT[S]
+	window  []float64
+	norm    []float64 // synthesis gain per position within a hop, which makes the frames sum to unity
+	hop     int
+	in, out [][]S // per channel input frame and overlap-add accumulator
+	k       int   // position within the current hop
+	frame   []S
+	bins    []complex128
+	drained bool
+	tail    int // samples left to stream after the wrapped Streamer is drained
+}
+
+// NewSTFT returns an STFT of s with frames of size samples, taken every hop samples. The size must
+// be a power of two and hop must be between 1 and size, otherwise NewSTFT panics. Hops of size/4
+// are a good default.
+//
+// The window is used both for analysis and synthesis. It must either have the length of size, or
+// be nil, in which case the Hann window is used. The window may not be zero at every multiple of
+// hop, otherwise NewSTFT panics.
+//
+// The process function is called with the spectrum of each frame of each channel, which has
+// size/2+1 bins, as computed by FFT. It is called from Stream, so it must not block. If process is
+// nil, the spectra are left unchanged.
+func NewSTFT[S beep.Size, P beep.Point[S]](s beep.Streamer[S, P], size, hop int, window []float64, process func(channel int, bins []complex128)) *STFT[S, P] {
+	if hop <= 0 || hop > size {
+		panic(fmt.Errorf("spectrum: invalid hop size: %d", hop))
+	}
+	if window == nil {
+		window = Hann(size)
+	}
+	if len(window) != size {
+		panic(fmt.Errorf("spectrum: window length %d does not match size %d", len(window), size))
+	}
+
+	norm := make([]float64, hop)
+	for j := range norm {
+		var sum float64
+		for i := j; i < size; i += hop {
+			sum += window[i] * window[i]
+		}
+		if sum == 0 {
+			panic(fmt.Errorf("spectrum: window does not overlap-add with hop size %d", hop))
+		}
+		norm[j] = 1 / sum
+	}
+
+	var p P
+	ct := p.Count()
+
+	st := &STFT[S, P]{
+		s:       s,
+		process: process,
+		fft:     NewFFT[S](size),
+		window:  window,
+		norm:    norm,
+		hop:     hop,
+		in:      make([][]S, ct),
+		out:     make([][]S, ct),
+		frame:   make([]S, size),
+		tail:    size,
+	}
+	st.bins = make([]complex128, st.fft.Bins())
+	for c := 0; c < ct; c++ {
+		st.in[c] = make([]S, size)
+		st.out[c] = make([]S, size)
+	}
+	return st
+}
+
+// Stream streams the wrapped Streamer processed in the frequency domain.
+func (st *STFT[S, P]) Stream(samples []P) (n int, ok bool) {
+	for n < len(samples) {
+		if !st.drained {
+			sn, sok := st.s.Stream(samples[n:])
+			if !sok {
+				st.drained = true
+				if st.s.Err() != nil {
+					st.tail = 0
+				}
+				continue
+			}
+			points.Each(samples[n:n+sn], st.push)
+			n += sn
+			continue
+		}
+		if st.tail == 0 {
+			break
+		}
+		var zero P
+		samples[n] = zero
+		points.Each(samples[n:n+1], st.push)
+		st.tail--
+		n++
+	}
+	return n, n > 0
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (st *STFT[S, P]) Err() error {
+	return st.s.Err()
+}
+
+// Latency returns the number of samples by which the output lags behind the input, which is the
+// frame size.
+func (st *STFT[S, P]) Latency() int {
+	return len(st.frame)
+}
+
+// push feeds the channels of one input sample and replaces them by one output sample.
+func (st *STFT[S, P]) push(ch []S) {
+	size := len(st.frame)
+	for c := range st.in {
+		st.in[c][size-st.hop+st.k] = ch[c]
+		ch[c] = st.out[c][st.k]
+	}
+	st.k++
+	if st.k == st.hop {
+		st.k = 0
+		for c := range st.in {
+			st.processChannel(c)
+		}
+	}
+}
+
+func (st *STFT[S, P]) processChannel(c int) {
+	in, out := st.in[c], st.out[c]
+	size := len(st.frame)
+
+	for i := range st.frame {
+		st.frame[i] = in[i] * S(st.window[i])
+	}
+	st.fft.Forward(st.bins, st.frame)
+	if st.process != nil {
+		st.process(c, st.bins)
+	}
+	st.fft.Inverse(st.frame, st.bins)
+
+	// the first hop of the accumulator was streamed already
+	copy(out, out[st.hop:])
+	for i := size - st.hop; i < size; i++ {
+		out[i] = 0
+	}
+	for i := range st.frame {
+		out[i] += st.frame[i] * S(st.window[i]*st.norm[i%st.hop])
+	}
+
+	copy(in, in[st.hop:])
+}
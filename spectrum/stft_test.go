@@ -0,0 +1,81 @@
+package spectrum_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/spectrum"
+)
+
+func TestSTFTReconstructs(t *testing.T) {
+	for _, tc := range []struct {
+		size, hop int
+		window    []float64
+	}{
+		{256, 64, nil},
+		{256, 128, spectrum.Hamming(256)},
+		{512, 128, spectrum.BlackmanHarris(512)},
+		{64, 64, spectrum.Rectangular(64)},
+	} {
+		data := make([]beep.Stereo[float64], 3000)
+		for i := range data {
+			data[i] = beep.Stereo[float64]{rand.Float64()*2 - 1, rand.Float64()*2 - 1}
+		}
+		pos := 0
+		s := beep.StreamerFunc[float64, beep.Stereo[float64]](func(samples []beep.Stereo[float64]) (n int, ok bool) {
+			if pos >= len(data) {
+				return 0, false
+			}
+			n = copy(samples, data[pos:])
+			pos += n
+			return n, true
+		})
+
+		var calls int
+		st := spectrum.NewSTFT[float64, beep.Stereo[float64]](s, tc.size, tc.hop, tc.window, func(channel int, bins []complex128) {
+			if len(bins) != tc.size/2+1 {
+				t.Fatalf("got %d bins, want %d", len(bins), tc.size/2+1)
+			}
+			calls++
+		})
+
+		var got []beep.Stereo[float64]
+		buf := make([]beep.Stereo[float64], 333)
+		for {
+			n, ok := st.Stream(buf)
+			if !ok {
+				break
+			}
+			got = append(got, buf[:n]...)
+		}
+
+		lat := st.Latency()
+		if len(got) != len(data)+lat {
+			t.Fatalf("size %d, hop %d: got %d samples, want %d", tc.size, tc.hop, len(got), len(data)+lat)
+		}
+		if calls == 0 {
+			t.Fatalf("process was never called")
+		}
+		for i := range data {
+			for c := 0; c < 2; c++ {
+				if math.Abs(got[i+lat][c]-data[i][c]) > 1e-9 {
+					t.Fatalf("size %d, hop %d: sample %d channel %d: got %f, want %f", tc.size, tc.hop, i, c, got[i+lat][c], data[i][c])
+				}
+			}
+		}
+	}
+}
+
+func TestSTFTAllocs(t *testing.T) {
+	st := spectrum.NewSTFT[float64, beep.Stereo[float64]](beep.Silence[float64, beep.Stereo[float64]](-1), 256, 64, nil, nil)
+	buf := make([]beep.Stereo[float64], 512)
+	st.Stream(buf)
+	allocs := testing.AllocsPerRun(100, func() {
+		st.Stream(buf)
+	})
+	if allocs != 0 {
+		t.Errorf("Stream allocates %v times per call", allocs)
+	}
+}
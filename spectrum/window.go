@@ -0,0 +1,76 @@
+package spectrum
+
+import "math"
+
+// The window functions below return periodic windows of length n, which are the right choice for
+// spectral analysis and overlap-add processing. A periodic window of length n is the symmetric
+// window of length n+1 with the last coefficient dropped.
+
+// Rectangular returns a window of length n which leaves the signal unchanged.
+func Rectangular(n int) []float64 {
+	w := make([]float64, n)
+	for i := range w {
+		w[i] = 1
+	}
+	return w
+}
+
+// Hann returns a Hann (raised cosine) window of length n. It's a good default with low leakage
+// far from the peak.
+func Hann(n int) []float64 {
+	return cosineSum(n, 0.5, 0.5)
+}
+
+// Hamming returns a Hamming window of length n. Its first sidelobe is lower than Hann's, but the
+// sidelobes fall off slower.
+func Hamming(n int) []float64 {
+	return cosineSum(n, 0.54, 0.46)
+}
+
+// BlackmanHarris returns a 4-term Blackman-Harris window of length n. Its sidelobes are below
+// -92 dB, at the cost of a wide main lobe.
+func BlackmanHarris(n int) []float64 {
+	return cosineSum(n, 0.35875, 0.48829, 0.14128, 0.01168)
+}
+
+// Kaiser returns a Kaiser window of length n. The beta argument trades the width of the main lobe
+// for the height of the sidelobes. A beta of 0 is the rectangular window, values around 5 resemble
+// Hamming, values around 9 resemble Blackman-Harris.
+func Kaiser(n int, beta float64) []float64 {
+	w := make([]float64, n)
+	denom := besselI0(beta)
+	for i := range w {
+		r := 2*float64(i)/float64(n) - 1
+		w[i] = besselI0(beta*math.Sqrt(1-r*r)) / denom
+	}
+	return w
+}
+
+// cosineSum returns the periodic generalized cosine window with coefficients a, alternating in
+// sign.
+func cosineSum(n int, a ...float64) []float64 {
+	w := make([]float64, n)
+	for i := range w {
+		x := 2 * math.Pi * float64(i) / float64(n)
+		sign := 1.0
+		for k, ak := range a {
+			w[i] += sign * ak * math.Cos(float64(k)*x)
+			sign = -sign
+		}
+	}
+	return w
+}
+
+// besselI0 computes the zeroth order modified Bessel function of the first kind using its power
+// series.
+func besselI0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	for k := 1; k < 500; k++ {
+		term *= (x / 2) / float64(k)
+		sum += term * term
+		if term*term < sum*1e-17 {
+			break
+		}
+	}
+	return sum
+}